@@ -0,0 +1,96 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// IsGitHubActions reports whether the process runs in a GitHub Actions runner
+func IsGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// WorkflowLogger writes progress lines using GitHub workflow commands (log groups, annotations, masking).
+// When disabled, it writes plain lines instead.
+type WorkflowLogger struct {
+	w        io.Writer
+	commands bool
+	mu       sync.Mutex
+}
+
+func NewWorkflowLogger(w io.Writer, commands bool) *WorkflowLogger {
+	return &WorkflowLogger{
+		w:        w,
+		commands: commands,
+	}
+}
+
+func (l *WorkflowLogger) Printf(format string, args ...interface{}) {
+	l.writeLine(fmt.Sprintf(format, args...))
+}
+
+func (l *WorkflowLogger) Warningf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if !l.commands {
+		l.writeLine("⚠️ " + message)
+		return
+	}
+
+	l.writeLine("::warning::" + escapeData(message))
+}
+
+func (l *WorkflowLogger) Errorf(title string, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if !l.commands {
+		l.writeLine("❌ " + title + ": " + message)
+		return
+	}
+
+	l.writeLine("::error title=" + escapeProperty(title) + "::" + escapeData(message))
+}
+
+func (l *WorkflowLogger) Group(name string) {
+	if !l.commands {
+		l.writeLine("\n" + name)
+		return
+	}
+
+	l.writeLine("::group::" + escapeData(name))
+}
+
+func (l *WorkflowLogger) EndGroup() {
+	if l.commands {
+		l.writeLine("::endgroup::")
+	}
+}
+
+// AddMask hides the value from the rest of the job logs
+func (l *WorkflowLogger) AddMask(value string) {
+	if l.commands && value != "" {
+		l.writeLine("::add-mask::" + escapeData(value))
+	}
+}
+
+func (l *WorkflowLogger) writeLine(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, _ = io.WriteString(l.w, line+"\n")
+}
+
+// escapeData escapes a workflow command message, see https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeProperty escapes a workflow command property value
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
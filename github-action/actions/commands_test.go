@@ -0,0 +1,49 @@
+package actions
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWorkflowLogger(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		commands bool
+		expected string
+	}{
+		{
+			commands: true,
+			expected: "::add-mask::secret-token\n" +
+				"::group::Deployment in progress\n" +
+				"Deployment ongoing: status DEPLOYING\n" +
+				"::endgroup::\n" +
+				"::warning::Environment queued for 5m0s%0Astill queued\n" +
+				"::error title=Qovery application deployment failed%3A front%2C back::state: BUILD_ERROR 100%25\n",
+		},
+		{
+			commands: false,
+			expected: "\nDeployment in progress\n" +
+				"Deployment ongoing: status DEPLOYING\n" +
+				"⚠️ Environment queued for 5m0s\nstill queued\n" +
+				"❌ Qovery application deployment failed: front, back: state: BUILD_ERROR 100%\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		logger := NewWorkflowLogger(&out, tc.commands)
+
+		// execute:
+		logger.AddMask("secret-token")
+		logger.Group("Deployment in progress")
+		logger.Printf("Deployment ongoing: status %s", "DEPLOYING")
+		logger.EndGroup()
+		logger.Warningf("Environment queued for %s\nstill queued", "5m0s")
+		logger.Errorf("Qovery application deployment failed: front, back", "state: %s 100%%", "BUILD_ERROR")
+
+		// verify:
+		if out.String() != tc.expected {
+			t.Fatalf("commands %v: expected:\n%q\nbut was:\n%q", tc.commands, tc.expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package actions implements the GitHub Actions runner integration: step outputs, job summary and workflow commands.
+package actions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SetOutput sets a step output through the file pointed by GITHUB_OUTPUT.
+// It does nothing when not running in GitHub Actions.
+func SetOutput(name string, value string) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error while opening GITHUB_OUTPUT file: %w", err)
+	}
+	defer f.Close()
+
+	if !strings.ContainsAny(value, "\r\n") {
+		_, err = fmt.Fprintf(f, "%s=%s\n", name, value)
+		return err
+	}
+
+	// multiline values have to be wrapped in a random delimiter which cannot appear in the value
+	delimiter, err := newDelimiter()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+
+	return err
+}
+
+func newDelimiter() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "ghadelimiter_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,29 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestSetOutput(t *testing.T) {
+	// setup:
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	// execute:
+	err1 := SetOutput("environment-state", "DEPLOYED")
+	err2 := SetOutput("deployment-result", "{\n  \"environment_state\": \"DEPLOYED\"\n}")
+
+	// verify:
+	if err1 != nil || err2 != nil {
+		t.Fatalf("unexpected error: %v / %v", err1, err2)
+	}
+	data, _ := os.ReadFile(path)
+	expected := regexp.MustCompile("^environment-state=DEPLOYED\ndeployment-result<<(ghadelimiter_[0-9a-f]+)\n\\{\n  \"environment_state\": \"DEPLOYED\"\n\\}\n(ghadelimiter_[0-9a-f]+)\n$")
+	matches := expected.FindStringSubmatch(string(data))
+	if matches == nil || matches[1] != matches[2] {
+		t.Fatalf("unexpected GITHUB_OUTPUT content:\n%s", data)
+	}
+}
@@ -0,0 +1,25 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+)
+
+// AppendSummary appends Markdown to the job summary through the file pointed by GITHUB_STEP_SUMMARY.
+// It does nothing when not running in GitHub Actions.
+func AppendSummary(markdown string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error while opening GITHUB_STEP_SUMMARY file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, markdown)
+
+	return err
+}
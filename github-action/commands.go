@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github-action/actions"
+	"github-action/pkg"
+	"github-action/qovery"
+)
+
+// validateDeployInputs checks the deploy inputs before any call to the API is made
+func validateDeployInputs() {
+	ids := joinInputs(*databaseIds, *databaseId)
+	names := joinInputs(*databaseNames, *databaseName)
+	databaseIds = &ids
+	databaseNames = &names
+
+	// with a commit per application, those without one are deployed at the head of their branch rather than at the run commit
+	if *applicationCommitId == "" && *applicationCommits == "" && manifest == nil {
+		envCommitID := os.Getenv("GITHUB_SHA")
+		applicationCommitId = &envCommitID
+	}
+
+	if _, err := parseInputMap(*applicationCommits); err != nil {
+		logger.Errorf("Invalid Qovery action inputs", "app-commit-ids: %s", err)
+		os.Exit(1)
+	}
+
+	deployApp := (applicationIds != nil && *applicationIds != "") || (applicationNames != nil && *applicationNames != "")
+	deployDb := (databaseIds != nil && *databaseIds != "") || (databaseNames != nil && *databaseNames != "")
+	deployContainer := (containerIds != nil && *containerIds != "") || (containerNames != nil && *containerNames != "") || containerTagsByService()
+
+	if manifest != nil {
+		if deployApp || deployDb || deployContainer {
+			logger.Errorf("Invalid Qovery action inputs", "services are defined in the manifest %s, they can't be given as args as well.", *config)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if deployApp && *applicationCommitId == "" && *applicationCommits == "" {
+		logger.Errorf("Invalid Qovery action inputs", "commit ID shouldn't be empty: `app-commit-id` or `app-commit-ids` to be set in args or `GITHUB_SHA` env var to be set.")
+		os.Exit(1)
+	}
+
+	if deployContainer && (containerImageTags == nil || *containerImageTags == "") {
+		logger.Errorf("Invalid Qovery action inputs", "container-tag shouldn't be empty if you want to deploy a specific container")
+		os.Exit(1)
+	}
+
+	if !deployApp && !deployDb && !deployContainer {
+		logger.Errorf("Invalid Qovery action inputs", "'app-ids' or 'app-names' or 'db-ids' or 'db-names' or 'container-ids' or 'container-names' property must be defined.")
+		os.Exit(1)
+	}
+}
+
+func runDeploy(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, organizationId string, projectId string, environmentId string, deployOptions qovery.DeployOptions) {
+	var services pkg.ServicesDeployment
+	var dbIds []string
+	if manifest != nil {
+		var err error
+		services, dbIds, err = manifest.ResolveWithContext(ctx, qoveryAPIClient, environmentId, *applicationCommitId)
+		handleError(err)
+	} else {
+		services, dbIds = servicesFromInputs(ctx, qoveryAPIClient, environmentId)
+	}
+	handleError(applyApplicationCommits(ctx, qoveryAPIClient, environmentId, services))
+
+	if *dryRun {
+		runPlan(ctx, qoveryAPIClient, organizationId, projectId, environmentId, dbIds, services)
+		return
+	}
+
+	if len(dbIds) > 0 {
+		logger.Printf("Qovery database(s) '%s' deployment starting...", strings.Join(dbIds, ", "))
+	}
+	if len(services.Applications) > 0 || len(services.Containers) > 0 {
+		payload, _ := json.Marshal(services)
+		logger.Printf("Qovery service deployment starting...\n%s", payload)
+	}
+	result, err := deploy(ctx, qoveryAPIClient, environmentId, dbIds, services, deployOptions)
+	report(qoveryAPIClient, result, organizationId, projectId)
+	handleError(err)
+}
+
+// runPlan prints what the deployment would do, making no deployment call
+func runPlan(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, organizationId string, projectId string, environmentId string, dbIds []string, services pkg.ServicesDeployment) {
+	plan, err := qovery.PlanWithContext(ctx, qoveryAPIClient, environmentId, dbIds, services)
+	handleError(err)
+	plan.OrganizationID = organizationId
+	plan.ProjectID = projectId
+	if *skipUnchanged {
+		plan.SkipUpToDate()
+	}
+
+	logger.Printf("Qovery deployment plan (dry run, nothing is deployed):")
+	for _, line := range plan.Lines() {
+		logger.Printf("%s", line)
+	}
+
+	payload, _ := json.Marshal(plan)
+	setOutputs([]output{
+		{name: "organization-id", value: organizationId},
+		{name: "project-id", value: projectId},
+		{name: "environment-id", value: environmentId},
+		{name: "environment-state", value: plan.EnvironmentState},
+		{name: "plan", value: string(payload)},
+	})
+
+	if err := actions.AppendSummary(plan.MarkdownSummary()); err != nil {
+		logger.Warningf("Error while writing job summary: %s", err)
+	}
+}
+
+// applyApplicationCommits sets the commits given per application with `app-commit-ids`, taking precedence over any other commit
+func applyApplicationCommits(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, services pkg.ServicesDeployment) error {
+	commits, err := parseInputMap(*applicationCommits)
+	if err != nil || len(commits) == 0 {
+		return err
+	}
+
+	commitsById, err := qovery.ResolveServiceKeysWithContext(ctx, qoveryAPIClient, environmentId, qovery.ServiceTypeApplication, commits)
+	if err != nil {
+		return fmt.Errorf("app-commit-ids: %w", err)
+	}
+
+	for i, app := range services.Applications {
+		if commit, ok := commitsById[app.ApplicationId]; ok {
+			services.Applications[i].GitCommitId = commit
+			delete(commitsById, app.ApplicationId)
+		}
+	}
+	for id := range commitsById {
+		logger.Warningf("A commit is given for application %s but it is not deployed", id)
+	}
+
+	for _, app := range services.Applications {
+		if app.GitCommitId == "" {
+			logger.Printf("No commit given for application %s, the head of its branch is deployed", app.ApplicationId)
+		}
+	}
+
+	return nil
+}
+
+// servicesFromInputs resolves the services to deploy given as args
+func servicesFromInputs(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string) (pkg.ServicesDeployment, []string) {
+	deployApp := (applicationIds != nil && *applicationIds != "") || (applicationNames != nil && *applicationNames != "")
+	deployDb := (databaseIds != nil && *databaseIds != "") || (databaseNames != nil && *databaseNames != "")
+
+	var dbIds []string
+	if deployDb {
+		ids, err := getDatabaseIds(ctx, qoveryAPIClient, environmentId, databaseIds, databaseNames)
+		handleError(err)
+		dbIds = strings.Split(ids, ",")
+	}
+
+	if deployApp {
+		appsIds, err := getApplicationIds(ctx, qoveryAPIClient, environmentId, applicationIds, applicationNames)
+		handleError(err)
+		applicationIds = &appsIds
+	}
+
+	ids := strings.Split(*applicationIds, ",")
+	apps := make([]pkg.ApplicationDeployment, 0)
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		apps = append(apps, pkg.ApplicationDeployment{
+			ApplicationId: id,
+			GitCommitId:   *applicationCommitId,
+		})
+	}
+
+	containers, err := containersFromInputs(ctx, qoveryAPIClient, environmentId)
+	handleError(err)
+
+	services := pkg.ServicesDeployment{
+		Applications: apps,
+		Containers:   containers,
+	}
+
+	return services, dbIds
+}
+
+// deploy runs the deployment, cancelling it if the run is interrupted once Qovery accepted it.
+// Until then, the environment may be busy with the deployment of another run, which must be left alone.
+func deploy(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, dbIds []string, services pkg.ServicesDeployment, deployOptions qovery.DeployOptions) (*qovery.DeploymentResult, error) {
+	result, err := qovery.DeployWithContext(ctx, qoveryAPIClient, environmentId, dbIds, services, deployOptions)
+	if sig := interrupted(); sig != nil && err != nil && result.Launched() {
+		cancelDeployment(qoveryAPIClient, environmentId, sig, deployOptions)
+	}
+
+	return result, err
+}
+
+// cancelDeployment cancels the deployment launched by an interrupted run, waiting briefly for it to be canceled
+func cancelDeployment(qoveryAPIClient pkg.QoveryAPIClient, environmentId string, sig os.Signal, deployOptions qovery.DeployOptions) {
+	logger.Warningf("Run interrupted by %s, cancelling the Qovery deployment of environment %s", sig, environmentId)
+
+	// run context is done already, cancellation gets its own short deadline
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+
+	opts := deployOptions
+	opts.DeploymentTimeout = 0
+	if opts.PollInterval > time.Second {
+		opts.PollInterval = time.Second
+	}
+
+	if err := qovery.CancelDeploymentWithContext(ctx, qoveryAPIClient, environmentId, opts); err != nil {
+		logger.Warningf("Qovery deployment may still be running: %s", err)
+	}
+}
+
+// runStatus prints the state of the environment and of each of its services
+func runStatus(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string) {
+	status, err := qoveryAPIClient.GetEnvironmentStatusWithContext(ctx, environmentId)
+	handleError(err)
+	logger.Printf("Environment %s state: %s", environmentId, status.State)
+
+	services, err := qovery.ListServicesWithContext(ctx, qoveryAPIClient, environmentId)
+	handleError(err)
+
+	states := map[string]string{}
+	for i := range services {
+		service := &services[i]
+		if _, err := qovery.GetServiceStateWithContext(ctx, qoveryAPIClient, service); err != nil {
+			logger.Warningf("Error while trying to get %s %s status: %s", service.Type, service.ID, err)
+			continue
+		}
+		logger.Printf("%s %s (%s) state: %s", service.Type, service.Name, service.ID, service.State)
+		states[service.ID] = service.State
+	}
+
+	serviceStates, _ := json.Marshal(states)
+	setOutputs([]output{
+		{name: "environment-id", value: environmentId},
+		{name: "environment-state", value: string(status.State)},
+		{name: "services-state", value: string(serviceStates)},
+	})
+}
+
+// runWait waits for the environment to reach the given state
+func runWait(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, target pkg.EnvStatus, deployOptions qovery.DeployOptions) {
+	if !target.IsKnown() {
+		logger.Warningf("Unknown environment state %s, the wait may only end on timeout", target)
+	}
+
+	state, err := qovery.WaitForEnvironmentStateWithContext(ctx, qoveryAPIClient, environmentId, target, deployOptions)
+	setOutputs([]output{
+		{name: "environment-id", value: environmentId},
+		{name: "environment-state", value: string(state)},
+	})
+	handleError(err)
+}
+
+// runEnvironmentAction requests an action on the environment, then waits for the environment to reach the state the action leads to
+func runEnvironmentAction(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, name string, action func(context.Context, string) error, target pkg.EnvStatus, deployOptions qovery.DeployOptions) {
+	logger.Printf("Qovery environment '%s' %s requested...", environmentId, name)
+	handleError(action(ctx, environmentId))
+
+	state, err := qovery.WaitForEnvironmentActionWithContext(ctx, qoveryAPIClient, environmentId, target, deployOptions)
+	setOutputs([]output{
+		{name: "environment-id", value: environmentId},
+		{name: "environment-state", value: string(state)},
+	})
+	handleError(err)
+}
+
+// runList prints the applications, containers and databases of the environment
+func runList(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string) {
+	services, err := qovery.ListServicesWithContext(ctx, qoveryAPIClient, environmentId)
+	handleError(err)
+
+	for _, service := range services {
+		logger.Printf("%-12s %-36s %s", service.Type, service.ID, service.Name)
+	}
+
+	type listedService struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	listed := make([]listedService, 0, len(services))
+	for _, service := range services {
+		listed = append(listed, listedService{Type: service.Type, ID: service.ID, Name: service.Name})
+	}
+
+	payload, _ := json.Marshal(listed)
+	setOutputs([]output{
+		{name: "environment-id", value: environmentId},
+		{name: "services", value: string(payload)},
+	})
+}
+
+// containerTagsByService tells whether container tags are given by container, as name=tag or id=tag pairs
+func containerTagsByService() bool {
+	return strings.Contains(*containerImageTags, "=")
+}
+
+// containersFromInputs resolves the containers to deploy, given by ID, by name or both, along with their image tag.
+// Tags are given either as
+// - a single tag deployed to every container: `v1.2.0`
+// - tags by container name or ID: `worker=v1.2.0,3d1f3b0c-...=v1.1.0`, containers only listed there being deployed as well
+// - tags in the same order as containers, IDs first: `v1.2.0,v1.1.0`
+func containersFromInputs(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string) ([]pkg.ContainerDeployment, error) {
+	var ids []string
+	if *containerIds != "" || *containerNames != "" {
+		contIds, err := getContainerIds(ctx, qoveryAPIClient, environmentId, containerIds, containerNames)
+		if err != nil {
+			return nil, err
+		}
+		ids = strings.Split(contIds, ",")
+	}
+
+	containers := make([]pkg.ContainerDeployment, 0)
+	if containerTagsByService() {
+		tags, err := parseInputMap(*containerImageTags)
+		if err != nil {
+			return nil, fmt.Errorf("container-tags: %w", err)
+		}
+		tagsById, err := qovery.ResolveServiceKeysWithContext(ctx, qoveryAPIClient, environmentId, qovery.ServiceTypeContainer, tags)
+		if err != nil {
+			return nil, fmt.Errorf("container-tags: %w", err)
+		}
+
+		var tagOnlyIds []string
+		for id := range tagsById {
+			if !contains(ids, id) {
+				tagOnlyIds = append(tagOnlyIds, id)
+			}
+		}
+		sort.Strings(tagOnlyIds)
+		ids = append(ids, tagOnlyIds...)
+
+		var missing []string
+		for _, id := range ids {
+			tag, ok := tagsById[id]
+			if !ok {
+				missing = append(missing, id)
+				continue
+			}
+			containers = append(containers, pkg.ContainerDeployment{Id: id, ImageTag: tag})
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("container-tags: no image tag given for container(s) %s", strings.Join(missing, ", "))
+		}
+
+		return containers, nil
+	}
+
+	if len(ids) == 0 {
+		return containers, nil
+	}
+
+	tags := strings.Split(sanitizeInputIDsList(*containerImageTags), ",")
+	if len(tags) == 1 {
+		// a single tag is deployed to every container
+		for len(tags) < len(ids) {
+			tags = append(tags, tags[0])
+		}
+	}
+	if len(ids) != len(tags) {
+		return nil, errors.New("You don't have the same number of container Ids and image tags.")
+	}
+
+	for ix, id := range ids {
+		containers = append(containers, pkg.ContainerDeployment{
+			Id:       id,
+			ImageTag: tags[ix],
+		})
+	}
+
+	return containers, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
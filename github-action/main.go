@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github-action/actions"
 	"github-action/pkg"
 	"github-action/qovery"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 )
@@ -23,14 +30,59 @@ var (
 	applicationIds      = kingpin.Flag("app-ids", "Qovery application ID(s)").String()
 	applicationNames    = kingpin.Flag("app-names", "Qovery application name(s)").String()
 	applicationCommitId = kingpin.Flag("app-commit-id", "Application commit ID").String()
-	databaseId          = kingpin.Flag("db-id", "Qovery database ID").String()
-	databaseName        = kingpin.Flag("db-name", "Qovery database name").String()
+	applicationCommits  = kingpin.Flag("app-commit-ids", "Application commit IDs as name=sha or id=sha pairs separated by ,").String()
+	databaseIds         = kingpin.Flag("db-ids", "Qovery database ids separated by ,").String()
+	databaseNames       = kingpin.Flag("db-names", "Qovery database name(s)").String()
+	databaseId          = kingpin.Flag("db-id", "Qovery database ID, same as --db-ids").Hidden().String()
+	databaseName        = kingpin.Flag("db-name", "Qovery database name, same as --db-names").Hidden().String()
 	containerIds        = kingpin.Flag("container-ids", "Qovery container ids separated by ,").String()
 	containerNames      = kingpin.Flag("container-names", "Qovery container name(s)").String()
 	containerImageTags  = kingpin.Flag("container-tags", "Qovery container image tags separated by ,").String()
+	skipUnchanged       = kingpin.Flag("skip-unchanged", "Don't deploy the applications and containers already running the requested commit or image tag").Bool()
+	dryRun              = kingpin.Flag("dry-run", "Resolve the services to deploy and print the deployment plan, without deploying anything").Bool()
+	config              = kingpin.Flag("config", "Path to a YAML or JSON deployment manifest, e.g. "+qovery.DefaultManifestPath+", describing the services to deploy").String()
 	apiToken            = kingpin.Flag("api-token", "Qovery API token").Required().String()
+	workflowCommands    = kingpin.Flag("workflow-commands", "Use GitHub workflow commands for log groups, annotations and secret masking").Default(strconv.FormatBool(actions.IsGitHubActions())).Bool()
+	apiURL              = kingpin.Flag("api-url", "Qovery API base URL").Default("https://api.qovery.com").String()
+	caBundle            = kingpin.Flag("ca-bundle", "Path to a PEM file of additional CA certificates to trust").String()
+	recordHTTP          = kingpin.Flag("record-http", "Record every Qovery API exchange to this cassette file (API token redacted)").String()
+	replayHTTP          = kingpin.Flag("replay-http", "Replay Qovery API exchanges from this cassette file instead of reaching the API").String()
+	debugHTTP           = kingpin.Flag("debug-http", "Log every Qovery API call (method, URL, status, latency), API token redacted").Bool()
+	debugHTTPBody       = kingpin.Flag("debug-http-body", "Log request and response bodies as well when --debug-http is set").Bool()
+	apiRateLimit        = kingpin.Flag("api-rate-limit", "Maximum average number of Qovery API calls per second (0 for no limit)").Default("5").Float64()
+	apiRateBurst        = kingpin.Flag("api-rate-burst", "Maximum number of Qovery API calls allowed in a burst").Default("10").Int()
+	apiMaxRetries       = kingpin.Flag("api-max-retries", "Maximum number of retries of a failed Qovery API call").Default("5").Int()
+	apiRetryBudget      = kingpin.Flag("api-retry-budget", "Maximum time spent retrying a single Qovery API call").Default("2m").Duration()
+	apiTimeout          = kingpin.Flag("api-timeout", "Maximum duration of a single Qovery API call, retries included").Default("3m").Duration()
+	readyTimeout        = kingpin.Flag("ready-timeout", "Maximum wait for the environment to accept a deployment (0 for no limit)").Default("30m").Duration()
+	deploymentTimeout   = kingpin.Flag("deployment-timeout", "Maximum wait for the deployment to complete (0 for no limit)").Default("1h").Duration()
+	timeout             = kingpin.Flag("timeout", "Maximum duration of the whole run (0 for no limit)").Default("2h").Duration()
+	// global like every other flag, the action passing the same args whatever the command
+	waitFor = kingpin.Flag("for", "Environment state the wait command waits for").Default(string(pkg.EnvStatusDeployed)).String()
 )
 
+var (
+	deployCommand  = kingpin.Command("deploy", "Deploy applications, containers or a database and wait for the deployment to complete").Default()
+	statusCommand  = kingpin.Command("status", "Show the state of the environment and of its services")
+	waitCommand    = kingpin.Command("wait", "Wait for the environment to reach a state")
+	cancelCommand  = kingpin.Command("cancel", "Cancel the ongoing deployment of the environment and wait for it to be canceled")
+	stopCommand    = kingpin.Command("stop", "Stop the environment and wait for it to be stopped")
+	restartCommand = kingpin.Command("restart", "Restart the environment and wait for it to be restarted")
+	listCommand    = kingpin.Command("list", "List the applications, containers and databases of the environment")
+)
+
+var logger = actions.NewWorkflowLogger(os.Stdout, false)
+
+// manifest is the deployment manifest given with --config, nil if none
+var manifest *qovery.Manifest
+
+// interrupted returns the signal which interrupted the run, nil if none was received
+var interrupted = func() os.Signal { return nil }
+
+// cancelTimeout bounds how long an interrupted run waits for its deployment to be canceled,
+// the runner killing the container shortly after asking it to stop
+const cancelTimeout = 8 * time.Second
+
 func sanitizeInputIDsList(ids string) string {
 	// remove any whitespaces provided eventually in list inputs
 	// example: `\n id, id \n` => `id,id`
@@ -42,43 +94,43 @@ func sanitizeInputIDsList(ids string) string {
 	return strings.TrimSpace(strings.Join(sanitized, ","))
 }
 
-func getOrganizationId(qoveryAPIClient pkg.QoveryAPIClient, id *string, name *string) (string, error) {
+func getOrganizationId(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, id *string, name *string) (string, error) {
 	if id != nil && *id != "" {
 		return *id, nil
 	}
 
 	if name != nil && *name != "" {
-		return qovery.GetOrganizationIdByName(qoveryAPIClient, *name)
+		return qovery.GetOrganizationIdByNameWithContext(ctx, qoveryAPIClient, *name)
 	}
 
 	return "", errors.New("'org-id' or 'org-name' property must be defined")
 }
 
-func getProjectId(qoveryAPIClient pkg.QoveryAPIClient, orgId string, id *string, name *string) (string, error) {
+func getProjectId(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, orgId string, id *string, name *string) (string, error) {
 	if id != nil && *id != "" {
 		return *id, nil
 	}
 
 	if name != nil && *name != "" {
-		return qovery.GetProjectIdByName(qoveryAPIClient, orgId, *name)
+		return qovery.GetProjectIdByNameWithContext(ctx, qoveryAPIClient, orgId, *name)
 	}
 
 	return "", errors.New("'project-id' or 'project-name' property must be defined")
 }
 
-func getEnvironmentId(qoveryAPIClient pkg.QoveryAPIClient, projectId string, id *string, name *string) (string, error) {
+func getEnvironmentId(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, projectId string, id *string, name *string) (string, error) {
 	if id != nil && *id != "" {
 		return *id, nil
 	}
 
 	if name != nil && *name != "" {
-		return qovery.GetEnvironmentIdByName(qoveryAPIClient, projectId, *name)
+		return qovery.GetEnvironmentIdByNameWithContext(ctx, qoveryAPIClient, projectId, *name)
 	}
 
 	return "", errors.New("'env-id' or 'env-name' property must be defined")
 }
 
-func getApplicationIds(qoveryAPIClient pkg.QoveryAPIClient, envId string, id *string, name *string) (string, error) {
+func getApplicationIds(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, envId string, id *string, name *string) (string, error) {
 	if id != nil && *id != "" {
 		return sanitizeInputIDsList(*id), nil
 	}
@@ -86,7 +138,7 @@ func getApplicationIds(qoveryAPIClient pkg.QoveryAPIClient, envId string, id *st
 	if name != nil && *name != "" {
 		var ids []string
 		for _, sName := range strings.Split(*name, ",") {
-			id, err := qovery.GetApplicationIdByName(qoveryAPIClient, envId, sName)
+			id, err := qovery.GetApplicationIdByNameWithContext(ctx, qoveryAPIClient, envId, sName)
 			handleError(err)
 
 			ids = append(ids, id)
@@ -98,147 +150,312 @@ func getApplicationIds(qoveryAPIClient pkg.QoveryAPIClient, envId string, id *st
 	return "", errors.New("'app-ids' or 'app-names' property must be defined")
 }
 
-func getContainerIds(qoveryAPIClient pkg.QoveryAPIClient, envId string, id *string, name *string) (string, error) {
+func getContainerIds(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, envId string, id *string, name *string) (string, error) {
+	// containers can be given both by ID and by name
+	var ids []string
 	if id != nil && *id != "" {
-		return sanitizeInputIDsList(*id), nil
+		ids = append(ids, strings.Split(sanitizeInputIDsList(*id), ",")...)
 	}
 
 	if name != nil && *name != "" {
-		var ids []string
 		for _, sName := range strings.Split(sanitizeInputIDsList(*name), ",") {
-			id, err := qovery.GetContainerIdByName(qoveryAPIClient, envId, sName)
+			id, err := qovery.GetContainerIdByNameWithContext(ctx, qoveryAPIClient, envId, sName)
 			handleError(err)
 
-			ids = append(ids, id)
+			if !contains(ids, id) {
+				ids = append(ids, id)
+			}
 		}
+	}
 
+	if len(ids) > 0 {
 		return strings.Join(ids, ","), nil
 	}
 
 	return "", errors.New("'container-ids' or 'container-names' property must be defined")
 }
 
-func getDatabaseId(qoveryAPIClient pkg.QoveryAPIClient, envId string, id *string, name *string) (string, error) {
+func getDatabaseIds(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, envId string, id *string, name *string) (string, error) {
+	// databases can be given both by ID and by name
+	var ids []string
 	if id != nil && *id != "" {
-		return *id, nil
+		ids = append(ids, strings.Split(sanitizeInputIDsList(*id), ",")...)
 	}
 
 	if name != nil && *name != "" {
-		return qovery.GetDatabaseIdByName(qoveryAPIClient, envId, *name)
+		for _, sName := range strings.Split(sanitizeInputIDsList(*name), ",") {
+			id, err := qovery.GetDatabaseIdByNameWithContext(ctx, qoveryAPIClient, envId, sName)
+			handleError(err)
+
+			if !contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) > 0 {
+		return strings.Join(ids, ","), nil
 	}
 
-	return "", errors.New("'db-id' or 'db-name' property must be defined")
+	return "", errors.New("'db-ids' or 'db-names' property must be defined")
 }
 
-func handleError(err error) {
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+// parseInputMap reads a map input made of key=value pairs separated by ,
+// example: `front=0123abc, back=4567def` => {"front": "0123abc", "back": "4567def"}
+func parseInputMap(input string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, pair := range strings.Split(sanitizeInputIDsList(input), ",") {
+		if pair == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid pair '%s', expected key=value", pair)
+		}
+		if _, ok := values[key]; ok {
+			return nil, fmt.Errorf("'%s' is given more than once", key)
+		}
+		values[key] = value
 	}
+
+	return values, nil
 }
 
-func main() {
-	kingpin.Parse()
+// joinInputs merges list inputs given through several flags, e.g. `--db-ids` and its former single value `--db-id`
+func joinInputs(values ...string) string {
+	var joined []string
+	for _, value := range values {
+		if value = sanitizeInputIDsList(value); value != "" {
+			joined = append(joined, value)
+		}
+	}
+
+	return strings.Join(joined, ",")
+}
 
-	envCommitID := ""
-	if applicationCommitId == nil || *applicationCommitId == "" {
-		envCommitID = os.Getenv("GITHUB_SHA")
-		applicationCommitId = &envCommitID
+// useManifestReference sets the ID or name of a resource from the manifest, unless one of them is given as arg
+func useManifestReference(ref *qovery.ManifestReference, id *string, name *string) {
+	if ref == nil || *id != "" || *name != "" {
+		return
 	}
 
-	deployApp := (applicationIds != nil && *applicationIds != "") || (applicationNames != nil && *applicationNames != "")
-	deployDb := (databaseId != nil && *databaseId != "") || (databaseName != nil && *databaseName != "")
-	deployContainer := (containerIds != nil && *containerIds != "") || (containerNames != nil && *containerNames != "")
+	*id = ref.ID
+	*name = ref.Name
+}
 
-	if deployApp && (applicationCommitId == nil || *applicationCommitId == "") {
-		fmt.Println("error: commit ID shouldn't be empty: `app-commit-id` to be set in args or `GITHUB_SHA` env var to be set.")
-		os.Exit(1)
+// report publishes the deployment result as step outputs and job summary
+func report(qoveryAPIClient pkg.QoveryAPIClient, result *qovery.DeploymentResult, organizationId string, projectId string) {
+	result.OrganizationID = organizationId
+	result.ProjectID = projectId
+
+	// run context may be done already, service names are still worth a last call
+	names, err := qovery.ListServiceNamesWithContext(context.Background(), qoveryAPIClient, result.EnvironmentID)
+	if err != nil {
+		logger.Warningf("Error while trying to get service names: %s", err)
+	} else {
+		result.SetNames(names)
 	}
 
-	if deployContainer && (containerImageTags == nil || *containerImageTags == "") {
-		fmt.Println("error: container-tag shouldn't be empty if you want to deploy a specific container")
-		os.Exit(1)
+	writeOutputs(result)
+
+	if err := actions.AppendSummary(result.MarkdownSummary()); err != nil {
+		logger.Warningf("Error while writing job summary: %s", err)
 	}
+}
 
-	if !deployApp && !deployDb && !deployContainer {
-		fmt.Println("error: 'app-ids' or 'app-names' or 'db-id' or 'db-name' or 'container-ids' property must be defined.")
-		os.Exit(1)
+// writeOutputs exposes the deployment result as step outputs, see `outputs` in action.yml
+func writeOutputs(result *qovery.DeploymentResult) {
+	serviceStates, _ := json.Marshal(result.ServiceStates())
+	deploymentResult, _ := json.Marshal(result)
+
+	setOutputs([]output{
+		{name: "environment-state", value: result.EnvironmentState},
+		{name: "organization-id", value: result.OrganizationID},
+		{name: "project-id", value: result.ProjectID},
+		{name: "environment-id", value: result.EnvironmentID},
+		{name: "application-ids", value: strings.Join(result.ServiceIDs(qovery.ServiceTypeApplication), ",")},
+		{name: "container-ids", value: strings.Join(result.ServiceIDs(qovery.ServiceTypeContainer), ",")},
+		{name: "database-id", value: strings.Join(result.ServiceIDs(qovery.ServiceTypeDatabase), ",")},
+		{name: "database-ids", value: strings.Join(result.ServiceIDs(qovery.ServiceTypeDatabase), ",")},
+		{name: "services-state", value: string(serviceStates)},
+		{name: "deployment-result", value: string(deploymentResult)},
+	})
+}
+
+type output struct {
+	name  string
+	value string
+}
+
+func setOutputs(outputs []output) {
+	for _, output := range outputs {
+		if err := actions.SetOutput(output.name, output.value); err != nil {
+			logger.Warningf("Error while setting output %s: %s", output.name, err)
+		}
 	}
+}
 
-	qoveryAPIClient := pkg.NewQoveryAPIClient(
-		&http.Client{},
-		"https://api.qovery.com",
-		*apiToken,
-		0,
-	)
+// withInterruption returns a context canceled when the run is asked to stop with SIGTERM or SIGINT, e.g. when the workflow is cancelled
+func withInterruption(parent context.Context) (context.Context, func() os.Signal) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
 
-	organizationId, err := getOrganizationId(qoveryAPIClient, organizationId, organizationName)
-	handleError(err)
+	return interruptedBy(parent, signals)
+}
 
-	projectId, err := getProjectId(qoveryAPIClient, organizationId, projectId, projectName)
-	handleError(err)
+// interruptedBy returns a context canceled on the first signal received, and a function returning that signal
+func interruptedBy(parent context.Context, signals <-chan os.Signal) (context.Context, func() os.Signal) {
+	ctx, cancel := context.WithCancel(parent)
+
+	var mu sync.Mutex
+	var received os.Signal
+	go func() {
+		sig := <-signals
+		mu.Lock()
+		received = sig
+		mu.Unlock()
+		cancel()
+	}()
+
+	return ctx, func() os.Signal {
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+}
 
-	environmentId, err := getEnvironmentId(qoveryAPIClient, projectId, environmentId, environmentName)
-	handleError(err)
+// handleInterruption exits with 128 + the signal number, as shells do, if the run has been interrupted
+func handleInterruption() {
+	sig := interrupted()
+	if sig == nil {
+		return
+	}
 
-	if deployDb {
-		databaseId, err := getDatabaseId(qoveryAPIClient, environmentId, databaseId, databaseName)
-		handleError(err)
+	logger.Errorf("Qovery deployment cancelled", "run interrupted by %s", sig)
+	os.Exit(interruptionExitCode(sig))
+}
 
-		fmt.Printf("Qovery database '%s' deployment starting...\n", databaseId)
-		err = qovery.DeployDatabase(qoveryAPIClient, databaseId, environmentId)
-		handleError(err)
-		os.Exit(0)
+// interruptionExitCode returns 128 + the signal number, SIGTERM being assumed for signals without a number
+func interruptionExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
 	}
 
-	if deployApp {
-		appsIds, err := getApplicationIds(qoveryAPIClient, environmentId, applicationIds, applicationNames)
-		handleError(err)
-		applicationIds = &appsIds
+	return 128 + int(syscall.SIGTERM)
+}
+
+func handleError(err error) {
+	if err != nil {
+		handleInterruption()
+		logger.Errorf("Qovery deployment failed", "%s", err)
+		os.Exit(1)
 	}
+}
 
-	ids := strings.Split(*applicationIds, ",")
-	apps := make([]pkg.ApplicationDeployment, 0)
-	for _, id := range ids {
-		if id == "" {
-			continue
-		}
-		apps = append(apps, pkg.ApplicationDeployment{
-			ApplicationId: id,
-			GitCommitId:   *applicationCommitId,
-		})
+func newQoveryAPIClient() pkg.QoveryAPIClient {
+	retryPolicy := pkg.DefaultRetryPolicy
+	retryPolicy.MaxRetries = *apiMaxRetries
+	retryPolicy.Budget = *apiRetryBudget
+
+	transport, err := pkg.NewTransport(*caBundle)
+	handleError(err)
+
+	var httpClient pkg.HTTPClient = &http.Client{Transport: transport}
+	if *replayHTTP != "" {
+		cassette, err := pkg.LoadCassette(*replayHTTP)
+		handleError(err)
+		httpClient = pkg.NewReplayHTTPClient(cassette)
+	} else if *recordHTTP != "" {
+		httpClient = pkg.NewRecordingHTTPClient(httpClient, *recordHTTP, logger.Warningf)
+	}
+	if *debugHTTP {
+		httpClient = pkg.NewDebugHTTPClient(httpClient, os.Stderr, *debugHTTPBody)
 	}
+	// every attempt, retries included, takes a token
+	httpClient = pkg.NewRateLimitedHTTPClient(httpClient, pkg.NewRateLimiter(*apiRateLimit, *apiRateBurst))
+
+	return pkg.NewQoveryAPIClient(
+		pkg.NewRetryHTTPClient(httpClient, retryPolicy),
+		strings.TrimSuffix(*apiURL, "/"),
+		*apiToken,
+		*apiTimeout,
+	)
+}
+
+func main() {
+	command := kingpin.Parse()
 
-	if deployContainer {
-		contIds, err := getContainerIds(qoveryAPIClient, environmentId, containerIds, containerNames)
+	logger = actions.NewWorkflowLogger(os.Stdout, *workflowCommands)
+	logger.AddMask(*apiToken)
+
+	if *config != "" {
+		var err error
+		manifest, err = qovery.LoadManifest(*config)
 		handleError(err)
-		containerIds = &contIds
+		useManifestReference(manifest.Organization, organizationId, organizationName)
+		useManifestReference(manifest.Project, projectId, projectName)
+		useManifestReference(manifest.Environment, environmentId, environmentName)
 	}
 
-	ids = strings.Split(sanitizeInputIDsList(*containerIds), ",")
-	tags := strings.Split(*containerImageTags, ",")
-	if len(ids) != len(tags) {
-		fmt.Println("You don't have the same number of container Ids and image tags.")
-		os.Exit(1)
+	if command == deployCommand.FullCommand() {
+		validateDeployInputs()
 	}
 
-	containers := make([]pkg.ContainerDeployment, 0)
-	for ix, id := range ids {
-		if id == "" {
-			continue
-		}
-		containers = append(containers, pkg.ContainerDeployment{
-			Id:       id,
-			ImageTag: tags[ix],
-		})
+	qoveryAPIClient := newQoveryAPIClient()
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	ctx, interrupted = withInterruption(ctx)
+
+	deployOptions := qovery.DeployOptions{
+		ReadyTimeout:      *readyTimeout,
+		DeploymentTimeout: *deploymentTimeout,
+		PollInterval:      qovery.DefaultDeployOptions.PollInterval,
+		StaleStateDelay:   qovery.DefaultDeployOptions.StaleStateDelay,
+		SkipUpToDate:      *skipUnchanged,
+		Logger:            logger,
 	}
-	services := pkg.ServicesDeployment{
-		Applications: apps,
-		Containers:   containers,
+	if manifest != nil {
+		deployOptions = manifest.Options.Apply(deployOptions)
 	}
+	if *replayHTTP != "" {
+		// statuses are already recorded, no need to wait between polls
+		deployOptions.PollInterval = 0
+	}
+
+	organizationId, err := getOrganizationId(ctx, qoveryAPIClient, organizationId, organizationName)
+	handleError(err)
 
-	payload, _ := json.Marshal(services)
-	fmt.Printf("Qovery service deployment starting...\n%s\n", payload)
-	err = qovery.DeployServices(qoveryAPIClient, environmentId, services)
+	projectId, err := getProjectId(ctx, qoveryAPIClient, organizationId, projectId, projectName)
 	handleError(err)
+
+	environmentId, err := getEnvironmentId(ctx, qoveryAPIClient, projectId, environmentId, environmentName)
+	handleError(err)
+
+	switch command {
+	case deployCommand.FullCommand():
+		runDeploy(ctx, qoveryAPIClient, organizationId, projectId, environmentId, deployOptions)
+	case statusCommand.FullCommand():
+		runStatus(ctx, qoveryAPIClient, environmentId)
+	case waitCommand.FullCommand():
+		target := pkg.EnvStatusDeployed
+		if *waitFor != "" {
+			target = pkg.EnvStatus(strings.ToUpper(strings.TrimSpace(*waitFor)))
+		}
+		runWait(ctx, qoveryAPIClient, environmentId, target, deployOptions)
+	case cancelCommand.FullCommand():
+		runEnvironmentAction(ctx, qoveryAPIClient, environmentId, "cancel deployment", qoveryAPIClient.CancelEnvironmentDeploymentWithContext, pkg.EnvStatusCancelled, deployOptions)
+	case stopCommand.FullCommand():
+		runEnvironmentAction(ctx, qoveryAPIClient, environmentId, "stop", qoveryAPIClient.StopEnvironmentWithContext, pkg.EnvStatusStopped, deployOptions)
+	case restartCommand.FullCommand():
+		runEnvironmentAction(ctx, qoveryAPIClient, environmentId, "restart", qoveryAPIClient.RestartEnvironmentWithContext, pkg.EnvStatusRestarted, deployOptions)
+	case listCommand.FullCommand():
+		runList(ctx, qoveryAPIClient, environmentId)
+	}
 }
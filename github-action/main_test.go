@@ -1,6 +1,17 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github-action/pkg"
+	"github-action/qovery"
+	"github-action/qoverytest"
+)
 
 func TestSanitizeInputIDsList(t *testing.T) {
 	// setup:
@@ -25,3 +36,176 @@ func TestSanitizeInputIDsList(t *testing.T) {
 		}
 	}
 }
+
+func TestParseInputMap(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		input         string
+		expected      map[string]string
+		expectedError bool
+	}{
+		{input: "", expected: map[string]string{}},
+		{input: "\n front=abc, \n back = def \n", expected: map[string]string{"front": "abc", "back": "def"}},
+		{input: "front", expectedError: true},
+		{input: "front=", expectedError: true},
+		{input: "front=abc,front=def", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		// execute:
+		res, err := parseInputMap(tc.input)
+
+		// verify:
+		if tc.expectedError != (err != nil) {
+			t.Fatalf(`%q: expected error to be %v but was %v`, tc.input, tc.expectedError, err)
+		}
+		if !tc.expectedError && !reflect.DeepEqual(res, tc.expected) {
+			t.Fatalf(`%q: expected %v but was %v`, tc.input, tc.expected, res)
+		}
+	}
+}
+
+func TestContainersFromInputs(t *testing.T) {
+	// setup:
+	server := qoverytest.NewServer()
+	defer server.Close()
+	server.AddContainer("env-id", "worker-id", "worker")
+	server.AddContainer("env-id", "cron-id", "cron")
+
+	testCases := []struct {
+		ids           string
+		names         string
+		tags          string
+		expected      []pkg.ContainerDeployment
+		expectedError bool
+	}{
+		{ids: "worker-id", names: "cron", tags: "v1", expected: []pkg.ContainerDeployment{{Id: "worker-id", ImageTag: "v1"}, {Id: "cron-id", ImageTag: "v1"}}},
+		{ids: "worker-id,cron-id", tags: "v1,v2", expected: []pkg.ContainerDeployment{{Id: "worker-id", ImageTag: "v1"}, {Id: "cron-id", ImageTag: "v2"}}},
+		{tags: "cron=v2, worker-id=v1", expected: []pkg.ContainerDeployment{{Id: "cron-id", ImageTag: "v2"}, {Id: "worker-id", ImageTag: "v1"}}},
+		{names: "worker", tags: "cron=v2,worker=v1", expected: []pkg.ContainerDeployment{{Id: "worker-id", ImageTag: "v1"}, {Id: "cron-id", ImageTag: "v2"}}},
+		{names: "worker,cron", tags: "worker=v1", expectedError: true},
+		{ids: "worker-id,cron-id", tags: "v1,v2,v3", expectedError: true},
+		{tags: "unknown=v1", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		*containerIds, *containerNames, *containerImageTags = tc.ids, tc.names, tc.tags
+
+		// execute:
+		res, err := containersFromInputs(context.Background(), server.Client(), "env-id")
+
+		// verify:
+		if tc.expectedError != (err != nil) {
+			t.Fatalf(`%q: expected error to be %v but was %v`, tc.tags, tc.expectedError, err)
+		}
+		if !tc.expectedError && !reflect.DeepEqual(res, tc.expected) {
+			t.Fatalf(`%q: expected %v but was %v`, tc.tags, tc.expected, res)
+		}
+	}
+}
+
+func TestGetDatabaseIds(t *testing.T) {
+	// setup:
+	server := qoverytest.NewServer()
+	defer server.Close()
+	server.AddDatabase("env-id", "pg-id", "pg")
+	server.AddDatabase("env-id", "redis-id", "redis")
+
+	testCases := []struct {
+		ids           string
+		names         string
+		expected      string
+		expectedError bool
+	}{
+		{ids: "pg-id", expected: "pg-id"},
+		{names: "pg,redis", expected: "pg-id,redis-id"},
+		{ids: "pg-id", names: "redis", expected: "pg-id,redis-id"},
+		{ids: "pg-id", names: "pg", expected: "pg-id"},
+		{expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		// execute:
+		res, err := getDatabaseIds(context.Background(), server.Client(), "env-id", &tc.ids, &tc.names)
+
+		// verify:
+		if tc.expectedError != (err != nil) {
+			t.Fatalf(`%q %q: expected error to be %v but was %v`, tc.ids, tc.names, tc.expectedError, err)
+		}
+		if res != tc.expected {
+			t.Fatalf(`%q %q: expected "%s" but was "%s"`, tc.ids, tc.names, tc.expected, res)
+		}
+	}
+}
+
+func TestDeployInterrupted(t *testing.T) {
+	// setup:
+	defer func(previous func() os.Signal) { interrupted = previous }(interrupted)
+	opts := qovery.DeployOptions{ReadyTimeout: 5 * time.Second, DeploymentTimeout: 5 * time.Second, PollInterval: time.Millisecond}
+	testCases := []struct {
+		name            string
+		launched        bool
+		expectedActions []string
+	}{
+		{name: "before launch", expectedActions: nil},
+		{name: "after launch", launched: true, expectedActions: []string{"cancelDeployment env-id"}},
+	}
+
+	for _, tc := range testCases {
+		server := qoverytest.NewServer()
+		server.AddEnvironment("project-id", "env-id", "production")
+		server.AddDatabase("env-id", "db-id", "postgres")
+		server.OnDeploy("db-id", "DEPLOYING")
+		if !tc.launched {
+			// the environment is busy with the deployment of another run, refusing this one
+			server.FailAlways("POST", "/database/db-id/deploy", 409)
+		}
+		signals := make(chan os.Signal, 1)
+		var ctx context.Context
+		ctx, interrupted = interruptedBy(context.Background(), signals)
+		go func(server *qoverytest.Server, launched bool) {
+			time.Sleep(50 * time.Millisecond)
+			for launched && len(server.Deployments()) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			signals <- syscall.SIGTERM
+		}(server, tc.launched)
+
+		// execute:
+		_, err := deploy(ctx, server.Client(), "env-id", []string{"db-id"}, pkg.ServicesDeployment{}, opts)
+		actions := server.Actions()
+		server.Close()
+
+		// verify:
+		if err == nil {
+			t.Fatalf("%s: expected the interrupted deployment to fail", tc.name)
+		}
+		if interrupted() != syscall.SIGTERM {
+			t.Fatalf("%s: expected the run to be interrupted by SIGTERM but was %v", tc.name, interrupted())
+		}
+		if !reflect.DeepEqual(actions, tc.expectedActions) {
+			t.Fatalf("%s: expected actions %v but was %v", tc.name, tc.expectedActions, actions)
+		}
+	}
+}
+
+func TestInterruptionExitCode(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		signal   os.Signal
+		expected int
+	}{
+		{signal: syscall.SIGTERM, expected: 143},
+		{signal: os.Interrupt, expected: 130},
+	}
+
+	for _, tc := range testCases {
+		// execute:
+		code := interruptionExitCode(tc.signal)
+
+		// verify:
+		if code != tc.expected {
+			t.Fatalf("%s: expected exit code %d but was %d", tc.signal, tc.expected, code)
+		}
+	}
+}
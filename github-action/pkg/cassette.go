@@ -0,0 +1,212 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+const redactedValue = "REDACTED"
+
+// Cassette is a recording of the HTTP exchanges of a session with the Qovery API
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and the response, or transport error, it got
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code,omitempty"`
+	Status     string      `json:"status,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := Cassette{}
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("error while reading cassette %s: %w", path, err)
+	}
+
+	return &cassette, nil
+}
+
+func (c Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+type recordingHTTPClient struct {
+	c        HTTPClient
+	path     string
+	warnf    func(format string, args ...interface{})
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecordingHTTPClient wraps an HTTPClient so every exchange is appended to the cassette file at path, API token redacted.
+// The cassette is saved after each exchange so it is kept even if the process is killed.
+// A failure to save it doesn't fail the exchange, it is reported to warnf, or to stderr if nil.
+func NewRecordingHTTPClient(c HTTPClient, path string, warnf func(format string, args ...interface{})) HTTPClient {
+	if warnf == nil {
+		warnf = func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, "⚠️ "+format+"\n", args...)
+		}
+	}
+
+	return &recordingHTTPClient{
+		c:     c,
+		path:  path,
+		warnf: warnf,
+	}
+}
+
+func (r *recordingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redactHeaders(req.Header),
+			Body:   string(reqBody),
+		},
+	}
+
+	resp, err := r.c.Do(req)
+	if err != nil {
+		interaction.Response.Error = err.Error()
+	} else {
+		respBody, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if readErr != nil {
+			return nil, readErr
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		interaction.Response.StatusCode = resp.StatusCode
+		interaction.Response.Status = resp.Status
+		interaction.Response.Header = redactHeaders(resp.Header)
+		interaction.Response.Body = string(respBody)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if saveErr := r.cassette.Save(r.path); saveErr != nil {
+		r.warnf("Error while saving HTTP cassette %s: %s", r.path, saveErr)
+	}
+
+	return resp, err
+}
+
+type replayHTTPClient struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayHTTPClient returns an HTTPClient serving the exchanges of a cassette instead of reaching the network.
+// Each request is answered by the first unused interaction with the same method, path and query, whatever the API base URL.
+func NewReplayHTTPClient(cassette *Cassette) HTTPClient {
+	return &replayHTTPClient{
+		cassette: cassette,
+		used:     make([]bool, len(cassette.Interactions)),
+	}
+}
+
+func (r *replayHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !matchesRecordedRequest(req, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+
+		if interaction.Response.Error != "" {
+			return nil, errors.New(interaction.Response.Error)
+		}
+
+		return &http.Response{
+			StatusCode: interaction.Response.StatusCode,
+			Status:     interaction.Response.Status,
+			Header:     interaction.Response.Header.Clone(),
+			Body:       io.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			Request:    req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction left for %s %s", req.Method, req.URL.RequestURI())
+}
+
+func matchesRecordedRequest(req *http.Request, recorded RecordedRequest) bool {
+	if req.Method != recorded.Method {
+		return false
+	}
+
+	recordedReq, err := http.NewRequest(recorded.Method, recorded.URL, nil)
+	if err != nil {
+		return false
+	}
+
+	return req.URL.RequestURI() == recordedReq.URL.RequestURI()
+}
+
+// readRequestBody returns the request body, leaving the request able to send it again
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// redactHeaders returns a copy of the headers with credentials hidden
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range []string{"Authorization", "Cookie", "Set-Cookie"} {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, redactedValue)
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplayCassette(t *testing.T) {
+	// setup:
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	polls := 0
+	c := fakeHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+		polls++
+		if polls == 1 {
+			return jsonResponse(200, EnvironmentStatus{ID: "env-id", State: EnvStatusDeploying}), nil
+		}
+		return jsonResponse(200, EnvironmentStatus{ID: "env-id", State: EnvStatusDeployed}), nil
+	}}
+	recorder := NewQoveryAPIClient(NewRecordingHTTPClient(c, path, nil), "https://api.qovery.test", "secret-token", 0)
+
+	// execute:
+	for i := 0; i < 2; i++ {
+		if _, err := recorder.GetEnvironmentStatus("env-id"); err != nil {
+			t.Fatalf("unexpected error while recording: %s", err)
+		}
+	}
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("unexpected error while loading cassette: %s", err)
+	}
+	replayer := NewQoveryAPIClient(NewReplayHTTPClient(cassette), "http://localhost:8080", "another-token", 0)
+	first, firstErr := replayer.GetEnvironmentStatus("env-id")
+	second, secondErr := replayer.GetEnvironmentStatus("env-id")
+	_, exhaustedErr := replayer.GetEnvironmentStatus("env-id")
+
+	// verify:
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret-token") {
+		t.Fatalf("expected API token to be redacted from cassette")
+	}
+	if len(cassette.Interactions) != 2 {
+		t.Fatalf("expected 2 recorded interactions but was %d", len(cassette.Interactions))
+	}
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("unexpected error while replaying: %v / %v", firstErr, secondErr)
+	}
+	if first.State != EnvStatusDeploying || second.State != EnvStatusDeployed {
+		t.Fatalf("expected recorded states to be replayed in order but was %s then %s", first.State, second.State)
+	}
+	if exhaustedErr == nil {
+		t.Fatalf("expected an error once every recorded interaction has been replayed")
+	}
+}
+
+func TestRecordCassetteSaveFailure(t *testing.T) {
+	// setup:
+	path := filepath.Join(t.TempDir(), "missing", "cassette.json")
+	c := fakeHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(200, EnvironmentStatus{ID: "env-id", State: EnvStatusDeployed}), nil
+	}}
+	var warnings []string
+	warnf := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+	recorder := NewQoveryAPIClient(NewRecordingHTTPClient(c, path, warnf), "https://api.qovery.test", "secret-token", 0)
+
+	// execute:
+	_, err := recorder.GetEnvironmentStatus("env-id")
+
+	// verify:
+	if err != nil {
+		t.Fatalf("expected the exchange not to fail but was %s", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], path) {
+		t.Fatalf("expected a warning about the cassette not being saved but was %v", warnings)
+	}
+}
@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDebugBodySize limits how much of a body is printed by the debug HTTP client
+const maxDebugBodySize = 4 * 1024
+
+type debugHTTPClient struct {
+	c         HTTPClient
+	w         io.Writer
+	logBodies bool
+	mu        sync.Mutex
+}
+
+// NewDebugHTTPClient wraps an HTTPClient so method, URL, headers, status and latency of every exchange are written to w.
+// Credentials headers are always redacted. Request and response bodies are written as well when logBodies is set.
+func NewDebugHTTPClient(c HTTPClient, w io.Writer, logBodies bool) HTTPClient {
+	return &debugHTTPClient{
+		c:         c,
+		w:         w,
+		logBodies: logBodies,
+	}
+}
+
+func (d *debugHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if d.logBodies {
+		body, err := readRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = body
+	}
+
+	start := time.Now()
+	resp, err := d.c.Do(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	var log strings.Builder
+	if err != nil {
+		fmt.Fprintf(&log, "[http] %s %s -> error: %s (%s)\n", req.Method, req.URL, err, latency)
+	} else {
+		fmt.Fprintf(&log, "[http] %s %s -> %s (%s)\n", req.Method, req.URL, resp.Status, latency)
+	}
+	fmt.Fprintf(&log, "[http]   request headers: %s\n", formatHeaders(redactHeaders(req.Header)))
+
+	if d.logBodies {
+		if len(reqBody) > 0 {
+			fmt.Fprintf(&log, "[http]   request body: %s\n", truncateBody(reqBody))
+		}
+		if resp != nil {
+			respBody, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr != nil {
+				return nil, readErr
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(respBody))
+			fmt.Fprintf(&log, "[http]   response body: %s\n", truncateBody(respBody))
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, _ = io.WriteString(d.w, log.String())
+
+	return resp, err
+}
+
+func formatHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	formatted := make([]string, 0, len(names))
+	for _, name := range names {
+		formatted = append(formatted, name+"="+strings.Join(header[name], ","))
+	}
+
+	return strings.Join(formatted, " ")
+}
+
+func truncateBody(body []byte) string {
+	if len(body) > maxDebugBodySize {
+		return string(body[:maxDebugBodySize]) + fmt.Sprintf("... (%d bytes truncated)", len(body)-maxDebugBodySize)
+	}
+
+	return string(body)
+}
@@ -0,0 +1,36 @@
+package pkg
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDebugHTTPClientRedactsToken(t *testing.T) {
+	// setup:
+	c := fakeHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(404, map[string]string{"message": "application not found"}), nil
+	}}
+	var log bytes.Buffer
+	client := NewQoveryAPIClient(NewDebugHTTPClient(c, &log, true), "https://api.qovery.test", "secret-token", 0)
+
+	// execute:
+	_, err := client.GetApplicationStatus("app-id")
+
+	// verify:
+	if err == nil {
+		t.Fatalf("expected an error for a 404 response")
+	}
+	if strings.Contains(log.String(), "secret-token") {
+		t.Fatalf("expected API token to be redacted from debug log:\n%s", log.String())
+	}
+	for _, expected := range []string{"GET https://api.qovery.test/application/app-id/status -> 404 Not Found", "Authorization=" + redactedValue, "application not found"} {
+		if !strings.Contains(log.String(), expected) {
+			t.Fatalf("expected debug log to contain %q:\n%s", expected, log.String())
+		}
+	}
+	if !strings.Contains(err.Error(), "application not found") {
+		t.Fatalf("expected response body to still be readable by the client, error was: %s", err)
+	}
+}
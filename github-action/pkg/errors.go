@@ -0,0 +1,104 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// maxErrorBodySize limits how much of an error response body is read
+	maxErrorBodySize = 64 * 1024
+	// maxRawErrorMessageSize limits how much of a non JSON error body ends up in the error message
+	maxRawErrorMessageSize = 512
+)
+
+// APIError is returned by QoveryAPIClient calls when the Qovery API answers with an unexpected status code.
+// Use errors.As to retrieve it and tell failures apart.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Path       string
+	RequestID  string
+	Message    string
+	Detail     string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("qovery API error, status code: %s (%s %s)", e.Status, e.Method, e.Path)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Detail != "" {
+		msg += " - " + e.Detail
+	}
+	if e.RequestID != "" {
+		msg += " [request id: " + e.RequestID + "]"
+	}
+
+	return msg
+}
+
+// IsUnauthorized reports whether the API token is invalid or lacks the permissions for the call
+func (e *APIError) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// IsNotFound reports whether the requested resource doesn't exist
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsConflict reports whether the call conflicts with the resource state, typically a deployment already running
+func (e *APIError) IsConflict() bool {
+	return e.StatusCode == http.StatusConflict
+}
+
+// IsRateLimited reports whether the call has been rejected by the API rate limiting
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+type apiErrorBody struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Detail  string `json:"detail"`
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Path = resp.Request.URL.Path
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil || len(data) == 0 {
+		return apiErr
+	}
+
+	body := apiErrorBody{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		// not a Qovery error payload (e.g. a proxy error page), keep it as is
+		apiErr.Message = strings.TrimSpace(string(data))
+		if len(apiErr.Message) > maxRawErrorMessageSize {
+			apiErr.Message = apiErr.Message[:maxRawErrorMessageSize] + "..."
+		}
+		return apiErr
+	}
+
+	apiErr.Message = body.Message
+	if apiErr.Message == "" {
+		apiErr.Message = body.Error
+	}
+	apiErr.Detail = body.Detail
+
+	return apiErr
+}
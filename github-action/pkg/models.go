@@ -1,31 +1,46 @@
 package pkg
 
+// ResultPage is a single page of a Qovery list endpoint.
+// `page` and `page_size` are only filled by endpoints actually paginating their results.
+type ResultPage[T any] struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Results  []T `json:"results"`
+}
+
 type Applications struct {
 	IDS      string
 	CommitID string
 }
 
 type Application struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID            string                   `json:"id"`
+	Name          string                   `json:"name"`
+	GitRepository ApplicationGitRepository `json:"git_repository"`
 }
 
-type ApplicationResult struct {
-	Results []Application `json:"results"`
+type ApplicationGitRepository struct {
+	Branch string `json:"branch"`
+	// DeployedCommitId is the commit currently deployed, empty if the application has never been deployed
+	DeployedCommitId string `json:"deployed_commit_id"`
 }
 
+type ApplicationResult = ResultPage[Application]
+
 type ApplicationDeployment struct {
 	ApplicationId string `json:"application_id"`
-	GitCommitId   string `json:"git_commit_id"`
+	// GitCommitId is the commit to deploy, the head of the application branch being deployed when empty
+	GitCommitId string `json:"git_commit_id,omitempty"`
 }
 
-type ContainerResult struct {
-	Results []Container `json:"results"`
-}
+type ContainerResult = ResultPage[Container]
 
 type Container struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ImageName string `json:"image_name"`
+	// Tag is the image tag currently deployed
+	Tag string `json:"tag"`
 }
 
 type ContainerDeployment struct {
@@ -43,33 +58,25 @@ type Database struct {
 	Name string `json:"name"`
 }
 
-type DatabaseResult struct {
-	Results []Database `json:"results"`
-}
+type DatabaseResult = ResultPage[Database]
 
 type Environment struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
 
-type EnvironmentResult struct {
-	Results []Environment `json:"results"`
-}
+type EnvironmentResult = ResultPage[Environment]
 
 type Project struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
 
-type ProjectResult struct {
-	Results []Project `json:"results"`
-}
+type ProjectResult = ResultPage[Project]
 
 type Organization struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
 
-type OrganizationResult struct {
-	Results []Organization `json:"results"`
-}
+type OrganizationResult = ResultPage[Organization]
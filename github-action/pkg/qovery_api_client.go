@@ -2,116 +2,21 @@ package pkg
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
-type EnvStatus string
-type AppStatus string
-type ContStatus string
-type DbStatus string
-
-// environments states
-const (
-	EnvStatusBuilding         = "BUILDING"
-	EnvStatusBuildError       = "BUILD_ERROR"
-	EnvStatusCancelled        = "CANCELED"
-	EnvStatusCancelling       = "CANCELING"
-	EnvStatusDeleted          = "DELETED"
-	EnvStatusDeleteError      = "DELETE_ERROR"
-	EnvStatusDeleteQueued     = "DELETE_QUEUED"
-	EnvStatusDeleting         = "DELETING"
-	EnvStatusDeployed         = "DEPLOYED"
-	EnvStatusDeploying        = "DEPLOYING"
-	EnvStatusDeploymentError  = "DEPLOYMENT_ERROR"
-	EnvStatusDeploymentQueued = "DEPLOYMENT_QUEUED"
-	EnvStatusQueued           = "QUEUED"
-	EnvStatusReady            = "READY"
-	EnvStatusStopped          = "STOPPED"
-	EnvStatusStopping         = "STOPPING"
-	EnvStatusStopError        = "STOP_ERROR"
-	EnvStatusStopQueued       = "STOP_QUEUED"
-	EnvStatusRestarted        = "RESTARTED"
-	EnvStatusRestartError     = "RESTART_ERROR"
-	EnvStatusUnknown          = "UNKNOWN"
-)
-
-// application states
-const (
-	AppStatusBuilding         = "BUILDING"
-	AppStatusBuildError       = "BUILD_ERROR"
-	AppStatusCanceled         = "CANCELED"
-	AppStatusCanceling        = "CANCELING"
-	AppStatusDeleted          = "DELETED"
-	AppStatusDeleteError      = "DELETE_ERROR"
-	AppStatusDeleteQueued     = "DELETE_QUEUED"
-	AppStatusDeleting         = "DELETING"
-	AppStatusDeployed         = "DEPLOYED"
-	AppStatusDeploying        = "DEPLOYING"
-	AppStatusDeploymentError  = "DEPLOYMENT_ERROR"
-	AppStatusDeploymentQueued = "DEPLOYMENT_QUEUED"
-	AppStatusQueued           = "QUEUED"
-	AppStatusReady            = "READY"
-	AppStatusStopped          = "STOPPED"
-	AppStatusStopping         = "STOPPING"
-	AppStatusStopError        = "STOP_ERROR"
-	AppStatusStopQueued       = "STOP_QUEUED"
-	AppStatusRestarted        = "RESTARTED"
-	AppStatusRestartError     = "RESTART_ERROR"
-	AppStatusUnknown          = "UNKNOWN"
-)
-
-// container states
 const (
-	ContStatusBuilding         = "BUILDING"
-	ContStatusBuildError       = "BUILD_ERROR"
-	ContStatusCanceled         = "CANCELED"
-	ContStatusCanceling        = "CANCELING"
-	ContStatusDeleted          = "DELETED"
-	ContStatusDeleteError      = "DELETE_ERROR"
-	ContStatusDeleteQueued     = "DELETE_QUEUED"
-	ContStatusDeleting         = "DELETING"
-	ContStatusDeployed         = "DEPLOYED"
-	ContStatusDeploying        = "DEPLOYING"
-	ContStatusDeploymentError  = "DEPLOYMENT_ERROR"
-	ContStatusDeploymentQueued = "DEPLOYMENT_QUEUED"
-	ContStatusQueued           = "QUEUED"
-	ContStatusReady            = "READY"
-	ContStatusStopped          = "STOPPED"
-	ContStatusStopping         = "STOPPING"
-	ContStatusStopError        = "STOP_ERROR"
-	ContStatusStopQueued       = "STOP_QUEUED"
-	ContStatusRestarted        = "RESTARTED"
-	ContStatusRestartError     = "RESTART_ERROR"
-	ContStatusUnknown          = "UNKNOWN"
-)
-
-// database states
-const (
-	DbStatusBuilding         = "BUILDING"
-	DbStatusBuildError       = "BUILD_ERROR"
-	DbStatusCanceled         = "CANCELED"
-	DbStatusCanceling        = "CANCELING"
-	DbStatusDeleted          = "DELETED"
-	DbStatusDeleteError      = "DELETE_ERROR"
-	DbStatusDeleteQueued     = "DELETE_QUEUED"
-	DbStatusDeleting         = "DELETING"
-	DbStatusDeployed         = "DEPLOYED"
-	DbStatusDeploying        = "DEPLOYING"
-	DbStatusDeploymentError  = "DEPLOYMENT_ERROR"
-	DbStatusDeploymentQueued = "DEPLOYMENT_QUEUED"
-	DbStatusQueued           = "QUEUED"
-	DbStatusReady            = "READY"
-	DbStatusStopped          = "STOPPED"
-	DbStatusStopping         = "STOPPING"
-	DbStatusStopError        = "STOP_ERROR"
-	DbStatusStopQueued       = "STOP_QUEUED"
-	DbStatusRestarted        = "RESTARTED"
-	DbtStatusRestartError    = "RESTART_ERROR"
-	DbStatusUnknown          = "UNKNOWN"
+	// listPageSize is the number of results asked for each page of a list endpoint
+	listPageSize = 100
+	// maxListPages protects against endless pagination, 100k results is way above any known organization
+	maxListPages = 1000
 )
 
 type EnvironmentStatus struct {
@@ -173,19 +78,43 @@ type HTTPClient interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+// QoveryAPIClient exposes the Qovery API calls used by the action.
+// Each call has a `WithContext` variant allowing callers to cancel it or set a deadline.
 type QoveryAPIClient interface {
 	DeployServices(environmentId string, services ServicesDeployment) error
+	DeployServicesWithContext(ctx context.Context, environmentId string, services ServicesDeployment) error
 	DeployDatabase(database Database) error
+	DeployDatabaseWithContext(ctx context.Context, database Database) error
+	CancelEnvironmentDeployment(environmentId string) error
+	CancelEnvironmentDeploymentWithContext(ctx context.Context, environmentId string) error
+	StopEnvironment(environmentId string) error
+	StopEnvironmentWithContext(ctx context.Context, environmentId string) error
+	RestartEnvironment(environmentId string) error
+	RestartEnvironmentWithContext(ctx context.Context, environmentId string) error
+	GetApplication(applicationId string) (*Application, error)
+	GetApplicationWithContext(ctx context.Context, applicationId string) (*Application, error)
+	GetContainer(containerId string) (*Container, error)
+	GetContainerWithContext(ctx context.Context, containerId string) (*Container, error)
 	GetEnvironmentStatus(environmentId string) (*EnvironmentStatus, error)
+	GetEnvironmentStatusWithContext(ctx context.Context, environmentId string) (*EnvironmentStatus, error)
 	GetApplicationStatus(applicationId string) (*ApplicationStatus, error)
+	GetApplicationStatusWithContext(ctx context.Context, applicationId string) (*ApplicationStatus, error)
 	GetContainerStatus(containerId string) (*ContainerStatus, error)
+	GetContainerStatusWithContext(ctx context.Context, containerId string) (*ContainerStatus, error)
 	GetDatabaseStatus(databaseId string) (*DatabaseStatus, error)
+	GetDatabaseStatusWithContext(ctx context.Context, databaseId string) (*DatabaseStatus, error)
 	ListOrganizations() ([]Organization, error)
+	ListOrganizationsWithContext(ctx context.Context) ([]Organization, error)
 	ListProjects(organizationId string) ([]Project, error)
+	ListProjectsWithContext(ctx context.Context, organizationId string) ([]Project, error)
 	ListEnvironments(projectId string) ([]Environment, error)
+	ListEnvironmentsWithContext(ctx context.Context, projectId string) ([]Environment, error)
 	ListApplications(environmentId string) ([]Application, error)
+	ListApplicationsWithContext(ctx context.Context, environmentId string) ([]Application, error)
 	ListContainers(environmentId string) ([]Container, error)
+	ListContainersWithContext(ctx context.Context, environmentId string) ([]Container, error)
 	ListDatabases(environmentId string) ([]Database, error)
+	ListDatabasesWithContext(ctx context.Context, environmentId string) ([]Database, error)
 }
 
 type qoveryAPIClient struct {
@@ -204,14 +133,38 @@ func NewQoveryAPIClient(c HTTPClient, baseURL string, apiToken string, timeout t
 	}
 }
 
-func (a qoveryAPIClient) DeployServices(environmentId string, services ServicesDeployment) error {
-	jsonValue, _ := json.Marshal(services)
+// withTimeout bounds a single API call, retries included, by the client timeout if any
+func (a qoveryAPIClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
 
-	req, err := http.NewRequest("POST", a.baseURL+"/environment/"+environmentId+"/service/deploy", bytes.NewBuffer(jsonValue))
+	return context.WithTimeout(ctx, a.timeout)
+}
+
+func (a qoveryAPIClient) newRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
 
 	req.Header.Set("Authorization", "Token "+a.apiToken)
 	req.Header.Set("Content-Type", "application/json")
 
+	return req, nil
+}
+
+func (a qoveryAPIClient) DeployServices(environmentId string, services ServicesDeployment) error {
+	return a.DeployServicesWithContext(context.Background(), environmentId, services)
+}
+
+func (a qoveryAPIClient) DeployServicesWithContext(ctx context.Context, environmentId string, services ServicesDeployment) error {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	jsonValue, _ := json.Marshal(services)
+
+	req, err := a.newRequest(ctx, "POST", "/environment/"+environmentId+"/service/deploy", bytes.NewBuffer(jsonValue))
 	if err != nil {
 		return err
 	}
@@ -226,16 +179,19 @@ func (a qoveryAPIClient) DeployServices(environmentId string, services ServicesD
 	case 200:
 		return nil // deployment launched
 	default:
-		return fmt.Errorf("qovery API error, status code: %s", resp.Status)
+		return newAPIError(resp)
 	}
 }
 
 func (a qoveryAPIClient) DeployDatabase(database Database) error {
-	req, err := http.NewRequest("POST", a.baseURL+"/database/"+database.ID+"/deploy", nil)
+	return a.DeployDatabaseWithContext(context.Background(), database)
+}
 
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
+func (a qoveryAPIClient) DeployDatabaseWithContext(ctx context.Context, database Database) error {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
 
+	req, err := a.newRequest(ctx, "POST", "/database/"+database.ID+"/deploy", nil)
 	if err != nil {
 		return err
 	}
@@ -250,82 +206,79 @@ func (a qoveryAPIClient) DeployDatabase(database Database) error {
 	case 200:
 		return nil // deployment launched
 	default:
-		return fmt.Errorf("qovery API error, status code: %s", resp.Status)
+		return newAPIError(resp)
 	}
 }
 
-func (a qoveryAPIClient) GetEnvironmentStatus(environmentId string) (*EnvironmentStatus, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/environment/"+environmentId+"/status", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
-	if err != nil {
-		return nil, err
-	}
+func (a qoveryAPIClient) CancelEnvironmentDeployment(environmentId string) error {
+	return a.CancelEnvironmentDeploymentWithContext(context.Background(), environmentId)
+}
 
-	resp, err := a.c.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+func (a qoveryAPIClient) CancelEnvironmentDeploymentWithContext(ctx context.Context, environmentId string) error {
+	return a.environmentAction(ctx, environmentId, "cancelDeployment")
+}
 
-	switch resp.StatusCode {
-	case 200:
-		jsonData, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
+func (a qoveryAPIClient) StopEnvironment(environmentId string) error {
+	return a.StopEnvironmentWithContext(context.Background(), environmentId)
+}
 
-		envStatus := NewUnknownEnvironmentStatus(environmentId)
-		err = json.Unmarshal(jsonData, &envStatus)
+func (a qoveryAPIClient) StopEnvironmentWithContext(ctx context.Context, environmentId string) error {
+	return a.environmentAction(ctx, environmentId, "stop")
+}
 
-		if err != nil {
-			return nil, err
-		}
+func (a qoveryAPIClient) RestartEnvironment(environmentId string) error {
+	return a.RestartEnvironmentWithContext(context.Background(), environmentId)
+}
 
-		return &envStatus, nil
-	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
-	}
+func (a qoveryAPIClient) RestartEnvironmentWithContext(ctx context.Context, environmentId string) error {
+	return a.environmentAction(ctx, environmentId, "restart")
 }
 
-func (a qoveryAPIClient) GetContainerStatus(containerId string) (*ContainerStatus, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/container/"+containerId+"/status", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
+func (a qoveryAPIClient) environmentAction(ctx context.Context, environmentId string, action string) error {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	req, err := a.newRequest(ctx, "POST", "/environment/"+environmentId+"/"+action, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	resp, err := a.c.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	switch resp.StatusCode {
-	case 200:
-		jsonData, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
+	case 200, 202:
+		return nil // action launched
+	default:
+		return newAPIError(resp)
+	}
+}
 
-		contStatus := NewUnknownContainerStatus(containerId)
-		err = json.Unmarshal(jsonData, &contStatus)
+func (a qoveryAPIClient) GetApplication(applicationId string) (*Application, error) {
+	return a.GetApplicationWithContext(context.Background(), applicationId)
+}
 
-		if err != nil {
-			return nil, err
-		}
+func (a qoveryAPIClient) GetApplicationWithContext(ctx context.Context, applicationId string) (*Application, error) {
+	return getResource[Application](ctx, a, "/application/"+applicationId)
+}
 
-		return &contStatus, nil
-	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
-	}
+func (a qoveryAPIClient) GetContainer(containerId string) (*Container, error) {
+	return a.GetContainerWithContext(context.Background(), containerId)
 }
 
-func (a qoveryAPIClient) GetApplicationStatus(applicationId string) (*ApplicationStatus, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/application/"+applicationId+"/status", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
+func (a qoveryAPIClient) GetContainerWithContext(ctx context.Context, containerId string) (*Container, error) {
+	return getResource[Container](ctx, a, "/container/"+containerId)
+}
+
+// getResource fetches a single resource from its endpoint
+func getResource[T any](ctx context.Context, a qoveryAPIClient, path string) (*T, error) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	req, err := a.newRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -343,23 +296,26 @@ func (a qoveryAPIClient) GetApplicationStatus(applicationId string) (*Applicatio
 			return nil, err
 		}
 
-		appStatus := NewUnknownApplicationStatus(applicationId)
-		err = json.Unmarshal(jsonData, &appStatus)
-
-		if err != nil {
+		var resource T
+		if err := json.Unmarshal(jsonData, &resource); err != nil {
 			return nil, err
 		}
 
-		return &appStatus, nil
+		return &resource, nil
 	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
+		return nil, newAPIError(resp)
 	}
 }
 
-func (a qoveryAPIClient) GetDatabaseStatus(databseId string) (*DatabaseStatus, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/database/"+databseId+"/status", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
+func (a qoveryAPIClient) GetEnvironmentStatus(environmentId string) (*EnvironmentStatus, error) {
+	return a.GetEnvironmentStatusWithContext(context.Background(), environmentId)
+}
+
+func (a qoveryAPIClient) GetEnvironmentStatusWithContext(ctx context.Context, environmentId string) (*EnvironmentStatus, error) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	req, err := a.newRequest(ctx, "GET", "/environment/"+environmentId+"/status", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -377,23 +333,28 @@ func (a qoveryAPIClient) GetDatabaseStatus(databseId string) (*DatabaseStatus, e
 			return nil, err
 		}
 
-		dbStatus := NewUnknownDatabaseStatus(databseId)
-		err = json.Unmarshal(jsonData, &dbStatus)
+		envStatus := NewUnknownEnvironmentStatus(environmentId)
+		err = json.Unmarshal(jsonData, &envStatus)
 
 		if err != nil {
 			return nil, err
 		}
 
-		return &dbStatus, nil
+		return &envStatus, nil
 	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
+		return nil, newAPIError(resp)
 	}
 }
 
-func (a qoveryAPIClient) ListApplications(environmentId string) ([]Application, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/environment/"+environmentId+"/application", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
+func (a qoveryAPIClient) GetContainerStatus(containerId string) (*ContainerStatus, error) {
+	return a.GetContainerStatusWithContext(context.Background(), containerId)
+}
+
+func (a qoveryAPIClient) GetContainerStatusWithContext(ctx context.Context, containerId string) (*ContainerStatus, error) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	req, err := a.newRequest(ctx, "GET", "/container/"+containerId+"/status", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -411,22 +372,28 @@ func (a qoveryAPIClient) ListApplications(environmentId string) ([]Application,
 			return nil, err
 		}
 
-		res := ApplicationResult{}
-		err = json.Unmarshal(jsonData, &res)
+		contStatus := NewUnknownContainerStatus(containerId)
+		err = json.Unmarshal(jsonData, &contStatus)
+
 		if err != nil {
 			return nil, err
 		}
 
-		return res.Results, nil
+		return &contStatus, nil
 	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
+		return nil, newAPIError(resp)
 	}
 }
 
-func (a qoveryAPIClient) ListContainers(environmentId string) ([]Container, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/environment/"+environmentId+"/container", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
+func (a qoveryAPIClient) GetApplicationStatus(applicationId string) (*ApplicationStatus, error) {
+	return a.GetApplicationStatusWithContext(context.Background(), applicationId)
+}
+
+func (a qoveryAPIClient) GetApplicationStatusWithContext(ctx context.Context, applicationId string) (*ApplicationStatus, error) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	req, err := a.newRequest(ctx, "GET", "/application/"+applicationId+"/status", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -444,22 +411,28 @@ func (a qoveryAPIClient) ListContainers(environmentId string) ([]Container, erro
 			return nil, err
 		}
 
-		res := ContainerResult{}
-		err = json.Unmarshal(jsonData, &res)
+		appStatus := NewUnknownApplicationStatus(applicationId)
+		err = json.Unmarshal(jsonData, &appStatus)
+
 		if err != nil {
 			return nil, err
 		}
 
-		return res.Results, nil
+		return &appStatus, nil
 	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
+		return nil, newAPIError(resp)
 	}
 }
 
-func (a qoveryAPIClient) ListDatabases(environmentId string) ([]Database, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/environment/"+environmentId+"/database", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
+func (a qoveryAPIClient) GetDatabaseStatus(databaseId string) (*DatabaseStatus, error) {
+	return a.GetDatabaseStatusWithContext(context.Background(), databaseId)
+}
+
+func (a qoveryAPIClient) GetDatabaseStatusWithContext(ctx context.Context, databseId string) (*DatabaseStatus, error) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	req, err := a.newRequest(ctx, "GET", "/database/"+databseId+"/status", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -477,88 +450,96 @@ func (a qoveryAPIClient) ListDatabases(environmentId string) ([]Database, error)
 			return nil, err
 		}
 
-		res := DatabaseResult{}
-		err = json.Unmarshal(jsonData, &res)
+		dbStatus := NewUnknownDatabaseStatus(databseId)
+		err = json.Unmarshal(jsonData, &dbStatus)
+
 		if err != nil {
 			return nil, err
 		}
 
-		return res.Results, nil
+		return &dbStatus, nil
 	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
+		return nil, newAPIError(resp)
 	}
 }
 
-func (a qoveryAPIClient) ListEnvironments(projectId string) ([]Environment, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/project/"+projectId+"/environment", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
-	if err != nil {
-		return nil, err
-	}
+func (a qoveryAPIClient) ListApplications(environmentId string) ([]Application, error) {
+	return a.ListApplicationsWithContext(context.Background(), environmentId)
+}
 
-	resp, err := a.c.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+func (a qoveryAPIClient) ListApplicationsWithContext(ctx context.Context, environmentId string) ([]Application, error) {
+	return listAllPages[Application](ctx, a, "/environment/"+environmentId+"/application")
+}
 
-	switch resp.StatusCode {
-	case 200:
-		jsonData, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
+func (a qoveryAPIClient) ListContainers(environmentId string) ([]Container, error) {
+	return a.ListContainersWithContext(context.Background(), environmentId)
+}
 
-		res := EnvironmentResult{}
-		err = json.Unmarshal(jsonData, &res)
-		if err != nil {
-			return nil, err
-		}
+func (a qoveryAPIClient) ListContainersWithContext(ctx context.Context, environmentId string) ([]Container, error) {
+	return listAllPages[Container](ctx, a, "/environment/"+environmentId+"/container")
+}
 
-		return res.Results, nil
-	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
-	}
+func (a qoveryAPIClient) ListDatabases(environmentId string) ([]Database, error) {
+	return a.ListDatabasesWithContext(context.Background(), environmentId)
+}
+
+func (a qoveryAPIClient) ListDatabasesWithContext(ctx context.Context, environmentId string) ([]Database, error) {
+	return listAllPages[Database](ctx, a, "/environment/"+environmentId+"/database")
+}
+
+func (a qoveryAPIClient) ListEnvironments(projectId string) ([]Environment, error) {
+	return a.ListEnvironmentsWithContext(context.Background(), projectId)
+}
+
+func (a qoveryAPIClient) ListEnvironmentsWithContext(ctx context.Context, projectId string) ([]Environment, error) {
+	return listAllPages[Environment](ctx, a, "/project/"+projectId+"/environment")
 }
 
 func (a qoveryAPIClient) ListProjects(organizationId string) ([]Project, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/organization/"+organizationId+"/project", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
-	if err != nil {
-		return nil, err
-	}
+	return a.ListProjectsWithContext(context.Background(), organizationId)
+}
 
-	resp, err := a.c.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+func (a qoveryAPIClient) ListProjectsWithContext(ctx context.Context, organizationId string) ([]Project, error) {
+	return listAllPages[Project](ctx, a, "/organization/"+organizationId+"/project")
+}
 
-	switch resp.StatusCode {
-	case 200:
-		jsonData, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
+func (a qoveryAPIClient) ListOrganizations() ([]Organization, error) {
+	return a.ListOrganizationsWithContext(context.Background())
+}
 
-		res := ProjectResult{}
-		err = json.Unmarshal(jsonData, &res)
+func (a qoveryAPIClient) ListOrganizationsWithContext(ctx context.Context) ([]Organization, error) {
+	return listAllPages[Organization](ctx, a, "/organization")
+}
+
+// listAllPages fetches every page of a list endpoint and returns the concatenated results.
+// Endpoints not paginating their results answer without `page_size`, in which case the first page is all there is.
+func listAllPages[T any](ctx context.Context, a qoveryAPIClient, path string) ([]T, error) {
+	var results []T
+	for page := 1; page <= maxListPages; page++ {
+		res, err := listPage[T](ctx, a, path, page)
 		if err != nil {
 			return nil, err
 		}
 
-		return res.Results, nil
-	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
+		results = append(results, res.Results...)
+
+		if res.PageSize <= 0 || len(res.Results) < res.PageSize {
+			return results, nil
+		}
 	}
+
+	return nil, fmt.Errorf("qovery API error, %s has more than %d pages", path, maxListPages)
 }
 
-func (a qoveryAPIClient) ListOrganizations() ([]Organization, error) {
-	req, err := http.NewRequest("GET", a.baseURL+"/organization", nil)
-	req.Header.Set("Authorization", "Token "+a.apiToken)
-	req.Header.Set("Content-Type", "application/json")
+func listPage[T any](ctx context.Context, a qoveryAPIClient, path string, page int) (*ResultPage[T], error) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(listPageSize))
+
+	req, err := a.newRequest(ctx, "GET", path+"?"+query.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -576,14 +557,14 @@ func (a qoveryAPIClient) ListOrganizations() ([]Organization, error) {
 			return nil, err
 		}
 
-		res := OrganizationResult{}
+		res := ResultPage[T]{}
 		err = json.Unmarshal(jsonData, &res)
 		if err != nil {
 			return nil, err
 		}
 
-		return res.Results, nil
+		return &res, nil
 	default:
-		return nil, fmt.Errorf("qovery API error, status code: %s", resp.Status)
+		return nil, newAPIError(resp)
 	}
 }
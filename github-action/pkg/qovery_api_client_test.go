@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+type fakeHTTPClient struct {
+	do func(req *http.Request) (*http.Response, error)
+}
+
+func (f fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return f.do(req)
+}
+
+func jsonResponse(statusCode int, body interface{}) *http.Response {
+	jsonData, _ := json.Marshal(body)
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(jsonData)),
+	}
+}
+
+func TestListEnvironmentsFollowsPagination(t *testing.T) {
+	// setup:
+	total := 2*listPageSize + 3
+	requestedPages := 0
+	c := fakeHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+		requestedPages++
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+		pageSize, _ := strconv.Atoi(req.URL.Query().Get("page_size"))
+
+		res := ResultPage[Environment]{Page: page, PageSize: pageSize}
+		for i := (page - 1) * pageSize; i < page*pageSize && i < total; i++ {
+			res.Results = append(res.Results, Environment{ID: strconv.Itoa(i), Name: "env-" + strconv.Itoa(i)})
+		}
+		return jsonResponse(200, res), nil
+	}}
+	client := NewQoveryAPIClient(c, "https://api.qovery.test", "token", 0)
+
+	// execute:
+	envs, err := client.ListEnvironments("project-id")
+
+	// verify:
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(envs) != total {
+		t.Fatalf("expected %d environments but was %d", total, len(envs))
+	}
+	if envs[total-1].Name != "env-"+strconv.Itoa(total-1) {
+		t.Fatalf(`expected last environment to be "env-%d" but was "%s"`, total-1, envs[total-1].Name)
+	}
+	if requestedPages != 3 {
+		t.Fatalf("expected 3 pages to be requested but was %d", requestedPages)
+	}
+}
+
+func TestListEnvironmentsWithoutPagination(t *testing.T) {
+	// setup:
+	requestedPages := 0
+	c := fakeHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+		requestedPages++
+		return jsonResponse(200, map[string]interface{}{
+			"results": []Environment{{ID: "1", Name: "production"}, {ID: "2", Name: "staging"}},
+		}), nil
+	}}
+	client := NewQoveryAPIClient(c, "https://api.qovery.test", "token", 0)
+
+	// execute:
+	envs, err := client.ListEnvironments("project-id")
+
+	// verify:
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(envs) != 2 || requestedPages != 1 {
+		t.Fatalf("expected 2 environments from 1 page but got %d from %d pages", len(envs), requestedPages)
+	}
+}
+
+func TestDeployServicesReturnsAPIError(t *testing.T) {
+	// setup:
+	c := fakeHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+		resp := jsonResponse(409, map[string]string{
+			"error":   "Conflict",
+			"message": "a deployment is already running",
+			"detail":  "environment is DEPLOYING",
+		})
+		resp.Header.Set("X-Request-Id", "request-id")
+		resp.Request = req
+		return resp, nil
+	}}
+	client := NewQoveryAPIClient(c, "https://api.qovery.test", "token", 0)
+
+	// execute:
+	err := client.DeployServices("env-id", ServicesDeployment{})
+
+	// verify:
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError but was %v", err)
+	}
+	if !apiErr.IsConflict() || apiErr.IsNotFound() || apiErr.IsUnauthorized() || apiErr.IsRateLimited() {
+		t.Fatalf("expected a conflict error but was %d", apiErr.StatusCode)
+	}
+	if apiErr.Method != "POST" || apiErr.Path != "/environment/env-id/service/deploy" || apiErr.RequestID != "request-id" {
+		t.Fatalf("unexpected request details: %s %s %s", apiErr.Method, apiErr.Path, apiErr.RequestID)
+	}
+	if apiErr.Message != "a deployment is already running" || apiErr.Detail != "environment is DEPLOYING" {
+		t.Fatalf(`unexpected error message: "%s" / "%s"`, apiErr.Message, apiErr.Detail)
+	}
+}
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting the rate of Qovery API calls.
+// Clients sharing the same RateLimiter share the same budget, a nil RateLimiter doesn't limit anything.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing ratePerSecond calls on average, with bursts of up to burst calls
+func NewRateLimiter(ratePerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   ratePerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// Wait blocks until a call is allowed or the context is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return nil
+	}
+
+	return SleepWithContext(ctx, l.reserve())
+}
+
+// reserve takes a token from the bucket and returns how long to wait before it is actually available.
+// The bucket goes negative while tokens are reserved ahead, so concurrent callers queue up fairly.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+type rateLimitedHTTPClient struct {
+	c       HTTPClient
+	limiter *RateLimiter
+}
+
+// NewRateLimitedHTTPClient wraps an HTTPClient so every request waits for the rate limiter first.
+// Wrapped by NewRetryHTTPClient, each retried attempt takes a token as well.
+// Give the same RateLimiter to all clients of a process to share a single budget.
+func NewRateLimitedHTTPClient(c HTTPClient, limiter *RateLimiter) HTTPClient {
+	return &rateLimitedHTTPClient{c: c, limiter: limiter}
+}
+
+func (r rateLimitedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if err := r.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	return r.c.Do(req)
+}
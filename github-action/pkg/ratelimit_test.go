@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	// setup:
+	now := time.Now()
+	limiter := NewRateLimiter(2, 3)
+	limiter.last = now
+	limiter.now = func() time.Time { return now }
+
+	// execute & verify:
+	for i := 0; i < 3; i++ {
+		if wait := limiter.reserve(); wait != 0 {
+			t.Fatalf("expected burst call %d not to wait but was %s", i, wait)
+		}
+	}
+	if wait := limiter.reserve(); wait != 500*time.Millisecond {
+		t.Fatalf("expected call after the burst to wait 500ms but was %s", wait)
+	}
+	if wait := limiter.reserve(); wait != time.Second {
+		t.Fatalf("expected next call to queue behind the previous one and wait 1s but was %s", wait)
+	}
+
+	now = now.Add(10 * time.Second)
+	if wait := limiter.reserve(); wait != 0 {
+		t.Fatalf("expected bucket to be refilled after 10s but was %s", wait)
+	}
+}
+
+func TestRateLimitedRetries(t *testing.T) {
+	// setup:
+	now := time.Now()
+	limiter := NewRateLimiter(1, 3)
+	limiter.last = now
+	limiter.now = func() time.Time { return now }
+	attempts := 0
+	c := fakeHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 3 {
+			return jsonResponse(503, nil), nil
+		}
+		return jsonResponse(200, nil), nil
+	}}
+	var waits []time.Duration
+	client := newTestRetryHTTPClient(NewRateLimitedHTTPClient(c, limiter), RetryPolicy{MaxRetries: 3}, &waits)
+	req, _ := http.NewRequest("GET", "https://api.qovery.test/organization", nil)
+
+	// execute:
+	resp, err := client.Do(req)
+
+	// verify:
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("expected the request to succeed after retries but was %v, %v", resp, err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts but was %d", attempts)
+	}
+	if wait := limiter.reserve(); wait != time.Second {
+		t.Fatalf("expected each attempt to take a token, leaving the next call to wait 1s, but was %s", wait)
+	}
+}
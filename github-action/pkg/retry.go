@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy describes how many times and for how long a failed request is retried
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt, 0 disables retries
+	MaxRetries int
+	// MinBackoff is the wait before the first retry, doubled on each following retry
+	MinBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts
+	MaxBackoff time.Duration
+	// Budget is the total time allowed for all attempts of a request, 0 means no limit
+	Budget time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	MinBackoff: 1 * time.Second,
+	MaxBackoff: 30 * time.Second,
+	Budget:     2 * time.Minute,
+}
+
+type retryHTTPClient struct {
+	c      HTTPClient
+	policy RetryPolicy
+	sleep  func(context.Context, time.Duration) error
+	jitter func(n int64) int64
+}
+
+// NewRetryHTTPClient wraps an HTTPClient so idempotent requests are retried on rate limiting,
+// gateway errors and connection resets, using exponential backoff with jitter and honoring `Retry-After`.
+func NewRetryHTTPClient(c HTTPClient, policy RetryPolicy) HTTPClient {
+	return &retryHTTPClient{
+		c:      c,
+		policy: policy,
+		sleep:  SleepWithContext,
+		jitter: rand.Int63n,
+	}
+}
+
+func (r retryHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return r.c.Do(req)
+	}
+
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		resp, err := r.c.Do(req)
+		if !isRetryable(resp, err) || attempt >= r.policy.MaxRetries {
+			return resp, err
+		}
+
+		wait := r.backoff(attempt, resp)
+		if r.policy.Budget > 0 && time.Since(start)+wait > r.policy.Budget {
+			return resp, err
+		}
+
+		if resp != nil {
+			// body has to be consumed and closed for the connection to be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := r.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// backoff returns how long to wait before the next attempt, `Retry-After` taking precedence over the exponential backoff
+func (r retryHTTPClient) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return retryAfter
+		}
+	}
+
+	backoff := r.policy.MinBackoff << attempt
+	if backoff <= 0 || backoff > r.policy.MaxBackoff {
+		backoff = r.policy.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	// equal jitter: keep at least half of the backoff so retries stay spread over time
+	half := backoff / 2
+	return half + time.Duration(r.jitter(int64(half)+1))
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	default:
+		return false
+	}
+}
+
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
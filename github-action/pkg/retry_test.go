@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newTestRetryHTTPClient(c HTTPClient, policy RetryPolicy, waits *[]time.Duration) HTTPClient {
+	return &retryHTTPClient{
+		c:      c,
+		policy: policy,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			*waits = append(*waits, d)
+			return nil
+		},
+		jitter: func(n int64) int64 { return 0 },
+	}
+}
+
+func TestRetryHTTPClient(t *testing.T) {
+	// setup:
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Second, MaxBackoff: 3 * time.Second}
+	// a 0 response status simulates a connection reset
+	testCases := []struct {
+		name           string
+		method         string
+		responses      []int
+		retryAfter     string
+		expectedStatus int
+		expectedWaits  []time.Duration
+	}{
+		{name: "success", method: "GET", responses: []int{200}, expectedStatus: 200},
+		{name: "not retryable status", method: "GET", responses: []int{404}, expectedStatus: 404},
+		{name: "gateway errors", method: "GET", responses: []int{502, 503, 504, 200}, expectedStatus: 200, expectedWaits: []time.Duration{500 * time.Millisecond, time.Second, 1500 * time.Millisecond}},
+		{name: "retries exhausted", method: "GET", responses: []int{503, 503, 503, 503, 200}, expectedStatus: 503, expectedWaits: []time.Duration{500 * time.Millisecond, time.Second, 1500 * time.Millisecond}},
+		{name: "retry after", method: "GET", responses: []int{429, 200}, retryAfter: "7", expectedStatus: 200, expectedWaits: []time.Duration{7 * time.Second}},
+		{name: "connection reset", method: "GET", responses: []int{0, 200}, expectedStatus: 200, expectedWaits: []time.Duration{500 * time.Millisecond}},
+		{name: "not idempotent", method: "POST", responses: []int{503, 200}, expectedStatus: 503},
+	}
+
+	for _, tc := range testCases {
+		attempts := 0
+		c := fakeHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if tc.responses[attempts-1] == 0 {
+				return nil, syscall.ECONNRESET
+			}
+			resp := jsonResponse(tc.responses[attempts-1], nil)
+			resp.Header.Set("Retry-After", tc.retryAfter)
+			return resp, nil
+		}}
+		var waits []time.Duration
+		client := newTestRetryHTTPClient(c, policy, &waits)
+		req, _ := http.NewRequest(tc.method, "https://api.qovery.test/organization", nil)
+
+		// execute:
+		resp, err := client.Do(req)
+
+		// verify:
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.name, err)
+		}
+		if resp.StatusCode != tc.expectedStatus {
+			t.Fatalf("%s: expected status %d but was %d", tc.name, tc.expectedStatus, resp.StatusCode)
+		}
+		if len(waits) != len(tc.expectedWaits) {
+			t.Fatalf("%s: expected waits %v but was %v", tc.name, tc.expectedWaits, waits)
+		}
+		for i := range waits {
+			if waits[i] != tc.expectedWaits[i] {
+				t.Fatalf("%s: expected waits %v but was %v", tc.name, tc.expectedWaits, waits)
+			}
+		}
+	}
+}
+
+func TestRetryHTTPClientBudget(t *testing.T) {
+	// setup:
+	policy := RetryPolicy{MaxRetries: 10, MinBackoff: time.Second, MaxBackoff: time.Second, Budget: 5 * time.Second}
+	c := fakeHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+		resp := jsonResponse(503, nil)
+		resp.Header.Set("Retry-After", "60")
+		return resp, nil
+	}}
+	var waits []time.Duration
+	client := newTestRetryHTTPClient(c, policy, &waits)
+	req, _ := http.NewRequest("GET", "https://api.qovery.test/organization", nil)
+
+	// execute:
+	resp, err := client.Do(req)
+
+	// verify:
+	if err != nil || resp.StatusCode != 503 {
+		t.Fatalf("expected 503 response once budget is exceeded, got %v / %v", resp, err)
+	}
+	if len(waits) != 0 {
+		t.Fatalf("expected no wait when Retry-After exceeds the budget but was %v", waits)
+	}
+}
@@ -0,0 +1,255 @@
+package pkg
+
+import "strings"
+
+// stateClass is how a state relates to the deployment, or any other action, of a resource
+type stateClass int
+
+const (
+	// stateUnrecognized is a state this client doesn't know about, e.g. introduced by a newer version of the API
+	stateUnrecognized stateClass = iota
+	// stateUnknown is the state the API reports when it doesn't know the state of a resource
+	stateUnknown
+	stateInProgress
+	stateDone
+	stateFailed
+)
+
+// stateClasses classifies every state the API is known to report, for all kinds of resources
+var stateClasses = map[string]stateClass{
+	"BUILDING":          stateInProgress,
+	"BUILD_ERROR":       stateFailed,
+	"CANCELED":          stateDone,
+	"CANCELING":         stateInProgress,
+	"DELETED":           stateDone,
+	"DELETE_ERROR":      stateFailed,
+	"DELETE_QUEUED":     stateInProgress,
+	"DELETING":          stateInProgress,
+	"DEPLOYED":          stateDone,
+	"DEPLOYING":         stateInProgress,
+	"DEPLOYMENT_ERROR":  stateFailed,
+	"DEPLOYMENT_QUEUED": stateInProgress,
+	"QUEUED":            stateInProgress,
+	"READY":             stateDone,
+	"STOPPED":           stateDone,
+	"STOPPING":          stateInProgress,
+	"STOP_ERROR":        stateFailed,
+	"STOP_QUEUED":       stateInProgress,
+	"RESTARTED":         stateDone,
+	"RESTARTING":        stateInProgress,
+	"RESTART_QUEUED":    stateInProgress,
+	"RESTART_ERROR":     stateFailed,
+	"UNKNOWN":           stateUnknown,
+}
+
+// classifyState returns the class of a state, guessing the class of unrecognized states from their name
+func classifyState(state string) (class stateClass, known bool) {
+	if class, ok := stateClasses[state]; ok {
+		return class, true
+	}
+
+	switch {
+	case strings.HasSuffix(state, "_ERROR"):
+		return stateFailed, false
+	case strings.HasSuffix(state, "QUEUED"), strings.HasSuffix(state, "ING"):
+		return stateInProgress, false
+	default:
+		return stateUnrecognized, false
+	}
+}
+
+// State is the state of an environment or a service, as reported by its status endpoint
+type State interface {
+	// IsKnown tells whether the state is one this client knows about, an unknown one being classified from its name
+	IsKnown() bool
+	// IsTerminal tells whether the state is the outcome of a deployment or any other action, rather than a step of it
+	IsTerminal() bool
+	// IsError tells whether the state is the outcome of a failed action
+	IsError() bool
+	// IsInProgress tells whether an action is queued or ongoing
+	IsInProgress() bool
+	// AcceptsDeployment tells whether a deployment can be started from the state
+	AcceptsDeployment() bool
+}
+
+func isTerminalState(state string) bool {
+	class, _ := classifyState(state)
+	return class == stateDone || class == stateFailed
+}
+
+func isErrorState(state string) bool {
+	class, _ := classifyState(state)
+	return class == stateFailed
+}
+
+func isInProgressState(state string) bool {
+	class, _ := classifyState(state)
+	return class == stateInProgress
+}
+
+// acceptsDeployment is true for the final states of any action but a deletion, and when the API doesn't know the state
+func acceptsDeployment(state string) bool {
+	class, _ := classifyState(state)
+	if class == stateUnknown {
+		return true
+	}
+
+	return (class == stateDone || class == stateFailed) && state != "DELETED" && state != "DELETE_ERROR"
+}
+
+func isKnownState(state string) bool {
+	_, known := classifyState(state)
+	return known
+}
+
+type EnvStatus string
+
+type AppStatus string
+
+type ContStatus string
+
+type DbStatus string
+
+// environments states
+const (
+	EnvStatusBuilding         EnvStatus = "BUILDING"
+	EnvStatusBuildError       EnvStatus = "BUILD_ERROR"
+	EnvStatusCancelled        EnvStatus = "CANCELED"
+	EnvStatusCancelling       EnvStatus = "CANCELING"
+	EnvStatusDeleted          EnvStatus = "DELETED"
+	EnvStatusDeleteError      EnvStatus = "DELETE_ERROR"
+	EnvStatusDeleteQueued     EnvStatus = "DELETE_QUEUED"
+	EnvStatusDeleting         EnvStatus = "DELETING"
+	EnvStatusDeployed         EnvStatus = "DEPLOYED"
+	EnvStatusDeploying        EnvStatus = "DEPLOYING"
+	EnvStatusDeploymentError  EnvStatus = "DEPLOYMENT_ERROR"
+	EnvStatusDeploymentQueued EnvStatus = "DEPLOYMENT_QUEUED"
+	EnvStatusQueued           EnvStatus = "QUEUED"
+	EnvStatusReady            EnvStatus = "READY"
+	EnvStatusStopped          EnvStatus = "STOPPED"
+	EnvStatusStopping         EnvStatus = "STOPPING"
+	EnvStatusStopError        EnvStatus = "STOP_ERROR"
+	EnvStatusStopQueued       EnvStatus = "STOP_QUEUED"
+	EnvStatusRestarted        EnvStatus = "RESTARTED"
+	EnvStatusRestarting       EnvStatus = "RESTARTING"
+	EnvStatusRestartQueued    EnvStatus = "RESTART_QUEUED"
+	EnvStatusRestartError     EnvStatus = "RESTART_ERROR"
+	EnvStatusUnknown          EnvStatus = "UNKNOWN"
+)
+
+// application states
+const (
+	AppStatusBuilding         AppStatus = "BUILDING"
+	AppStatusBuildError       AppStatus = "BUILD_ERROR"
+	AppStatusCanceled         AppStatus = "CANCELED"
+	AppStatusCanceling        AppStatus = "CANCELING"
+	AppStatusDeleted          AppStatus = "DELETED"
+	AppStatusDeleteError      AppStatus = "DELETE_ERROR"
+	AppStatusDeleteQueued     AppStatus = "DELETE_QUEUED"
+	AppStatusDeleting         AppStatus = "DELETING"
+	AppStatusDeployed         AppStatus = "DEPLOYED"
+	AppStatusDeploying        AppStatus = "DEPLOYING"
+	AppStatusDeploymentError  AppStatus = "DEPLOYMENT_ERROR"
+	AppStatusDeploymentQueued AppStatus = "DEPLOYMENT_QUEUED"
+	AppStatusQueued           AppStatus = "QUEUED"
+	AppStatusReady            AppStatus = "READY"
+	AppStatusStopped          AppStatus = "STOPPED"
+	AppStatusStopping         AppStatus = "STOPPING"
+	AppStatusStopError        AppStatus = "STOP_ERROR"
+	AppStatusStopQueued       AppStatus = "STOP_QUEUED"
+	AppStatusRestarted        AppStatus = "RESTARTED"
+	AppStatusRestarting       AppStatus = "RESTARTING"
+	AppStatusRestartQueued    AppStatus = "RESTART_QUEUED"
+	AppStatusRestartError     AppStatus = "RESTART_ERROR"
+	AppStatusUnknown          AppStatus = "UNKNOWN"
+)
+
+// container states
+const (
+	ContStatusBuilding         ContStatus = "BUILDING"
+	ContStatusBuildError       ContStatus = "BUILD_ERROR"
+	ContStatusCanceled         ContStatus = "CANCELED"
+	ContStatusCanceling        ContStatus = "CANCELING"
+	ContStatusDeleted          ContStatus = "DELETED"
+	ContStatusDeleteError      ContStatus = "DELETE_ERROR"
+	ContStatusDeleteQueued     ContStatus = "DELETE_QUEUED"
+	ContStatusDeleting         ContStatus = "DELETING"
+	ContStatusDeployed         ContStatus = "DEPLOYED"
+	ContStatusDeploying        ContStatus = "DEPLOYING"
+	ContStatusDeploymentError  ContStatus = "DEPLOYMENT_ERROR"
+	ContStatusDeploymentQueued ContStatus = "DEPLOYMENT_QUEUED"
+	ContStatusQueued           ContStatus = "QUEUED"
+	ContStatusReady            ContStatus = "READY"
+	ContStatusStopped          ContStatus = "STOPPED"
+	ContStatusStopping         ContStatus = "STOPPING"
+	ContStatusStopError        ContStatus = "STOP_ERROR"
+	ContStatusStopQueued       ContStatus = "STOP_QUEUED"
+	ContStatusRestarted        ContStatus = "RESTARTED"
+	ContStatusRestarting       ContStatus = "RESTARTING"
+	ContStatusRestartQueued    ContStatus = "RESTART_QUEUED"
+	ContStatusRestartError     ContStatus = "RESTART_ERROR"
+	ContStatusUnknown          ContStatus = "UNKNOWN"
+)
+
+// database states
+const (
+	DbStatusBuilding         DbStatus = "BUILDING"
+	DbStatusBuildError       DbStatus = "BUILD_ERROR"
+	DbStatusCanceled         DbStatus = "CANCELED"
+	DbStatusCanceling        DbStatus = "CANCELING"
+	DbStatusDeleted          DbStatus = "DELETED"
+	DbStatusDeleteError      DbStatus = "DELETE_ERROR"
+	DbStatusDeleteQueued     DbStatus = "DELETE_QUEUED"
+	DbStatusDeleting         DbStatus = "DELETING"
+	DbStatusDeployed         DbStatus = "DEPLOYED"
+	DbStatusDeploying        DbStatus = "DEPLOYING"
+	DbStatusDeploymentError  DbStatus = "DEPLOYMENT_ERROR"
+	DbStatusDeploymentQueued DbStatus = "DEPLOYMENT_QUEUED"
+	DbStatusQueued           DbStatus = "QUEUED"
+	DbStatusReady            DbStatus = "READY"
+	DbStatusStopped          DbStatus = "STOPPED"
+	DbStatusStopping         DbStatus = "STOPPING"
+	DbStatusStopError        DbStatus = "STOP_ERROR"
+	DbStatusStopQueued       DbStatus = "STOP_QUEUED"
+	DbStatusRestarted        DbStatus = "RESTARTED"
+	DbStatusRestarting       DbStatus = "RESTARTING"
+	DbStatusRestartQueued    DbStatus = "RESTART_QUEUED"
+	DbStatusRestartError     DbStatus = "RESTART_ERROR"
+	DbStatusUnknown          DbStatus = "UNKNOWN"
+)
+
+// DbtStatusRestartError is the former, misspelled, name of DbStatusRestartError
+//
+// Deprecated: use DbStatusRestartError.
+const DbtStatusRestartError = DbStatusRestartError
+
+func (s EnvStatus) IsKnown() bool           { return isKnownState(string(s)) }
+func (s EnvStatus) IsTerminal() bool        { return isTerminalState(string(s)) }
+func (s EnvStatus) IsError() bool           { return isErrorState(string(s)) }
+func (s EnvStatus) IsInProgress() bool      { return isInProgressState(string(s)) }
+func (s EnvStatus) AcceptsDeployment() bool { return acceptsDeployment(string(s)) }
+
+func (s AppStatus) IsKnown() bool           { return isKnownState(string(s)) }
+func (s AppStatus) IsTerminal() bool        { return isTerminalState(string(s)) }
+func (s AppStatus) IsError() bool           { return isErrorState(string(s)) }
+func (s AppStatus) IsInProgress() bool      { return isInProgressState(string(s)) }
+func (s AppStatus) AcceptsDeployment() bool { return acceptsDeployment(string(s)) }
+
+func (s ContStatus) IsKnown() bool           { return isKnownState(string(s)) }
+func (s ContStatus) IsTerminal() bool        { return isTerminalState(string(s)) }
+func (s ContStatus) IsError() bool           { return isErrorState(string(s)) }
+func (s ContStatus) IsInProgress() bool      { return isInProgressState(string(s)) }
+func (s ContStatus) AcceptsDeployment() bool { return acceptsDeployment(string(s)) }
+
+func (s DbStatus) IsKnown() bool           { return isKnownState(string(s)) }
+func (s DbStatus) IsTerminal() bool        { return isTerminalState(string(s)) }
+func (s DbStatus) IsError() bool           { return isErrorState(string(s)) }
+func (s DbStatus) IsInProgress() bool      { return isInProgressState(string(s)) }
+func (s DbStatus) AcceptsDeployment() bool { return acceptsDeployment(string(s)) }
+
+// service deployment statuses, telling whether a running service is up to date with its configuration
+const (
+	ServiceDeploymentStatusNeverDeployed = "NEVER_DEPLOYED"
+	ServiceDeploymentStatusOutOfDate     = "OUT_OF_DATE"
+	ServiceDeploymentStatusUpToDate      = "UP_TO_DATE"
+)
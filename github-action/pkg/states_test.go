@@ -0,0 +1,45 @@
+package pkg
+
+import "testing"
+
+func TestStateClassification(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		state             State
+		known             bool
+		terminal          bool
+		error             bool
+		inProgress        bool
+		acceptsDeployment bool
+	}{
+		{state: EnvStatusDeployed, known: true, terminal: true, acceptsDeployment: true},
+		{state: EnvStatusStopped, known: true, terminal: true, acceptsDeployment: true},
+		{state: AppStatusBuildError, known: true, terminal: true, error: true, acceptsDeployment: true},
+		{state: ContStatusDeploymentQueued, known: true, inProgress: true},
+		{state: DbStatusDeploying, known: true, inProgress: true},
+		{state: DbStatusDeleted, known: true, terminal: true},
+		{state: EnvStatusUnknown, known: true, acceptsDeployment: true},
+		{state: AppStatus("ROLLBACK_ERROR"), terminal: true, error: true, acceptsDeployment: true},
+		{state: AppStatus("MIGRATING"), inProgress: true},
+		{state: EnvStatus("SOMETHING_NEW")},
+	}
+
+	for _, tc := range testCases {
+		// execute & verify:
+		if tc.state.IsKnown() != tc.known {
+			t.Errorf("%s: expected IsKnown to be %v", tc.state, tc.known)
+		}
+		if tc.state.IsTerminal() != tc.terminal {
+			t.Errorf("%s: expected IsTerminal to be %v", tc.state, tc.terminal)
+		}
+		if tc.state.IsError() != tc.error {
+			t.Errorf("%s: expected IsError to be %v", tc.state, tc.error)
+		}
+		if tc.state.IsInProgress() != tc.inProgress {
+			t.Errorf("%s: expected IsInProgress to be %v", tc.state, tc.inProgress)
+		}
+		if tc.state.AcceptsDeployment() != tc.acceptsDeployment {
+			t.Errorf("%s: expected AcceptsDeployment to be %v", tc.state, tc.acceptsDeployment)
+		}
+	}
+}
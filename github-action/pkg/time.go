@@ -0,0 +1,19 @@
+package pkg
+
+import (
+	"context"
+	"time"
+)
+
+// SleepWithContext pauses for the given duration, returning the context error early if it is done before.
+func SleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
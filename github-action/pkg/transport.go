@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// NewTransport returns an HTTP transport going through the proxy set in HTTPS_PROXY / HTTP_PROXY / NO_PROXY env vars.
+// When caBundlePath is not empty, the PEM certificates it contains are trusted on top of the system ones.
+func NewTransport(caBundlePath string) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if caBundlePath == "" {
+		return transport, nil
+	}
+
+	pemCerts, err := os.ReadFile(caBundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading CA bundle: %w", err)
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if !rootCAs.AppendCertsFromPEM(pemCerts) {
+		return nil, fmt.Errorf("error: no PEM certificate found in CA bundle %s", caBundlePath)
+	}
+
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	return transport, nil
+}
@@ -1,12 +1,17 @@
 package qovery
 
 import (
+	"context"
 	"fmt"
 	"github-action/pkg"
 )
 
 func GetApplicationIdByName(qoveryAPIClient pkg.QoveryAPIClient, environmentId string, name string) (string, error) {
-	applications, err := qoveryAPIClient.ListApplications(environmentId)
+	return GetApplicationIdByNameWithContext(context.Background(), qoveryAPIClient, environmentId, name)
+}
+
+func GetApplicationIdByNameWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, name string) (string, error) {
+	applications, err := qoveryAPIClient.ListApplicationsWithContext(ctx, environmentId)
 	if err != nil {
 		return "", err
 	}
@@ -1,12 +1,17 @@
 package qovery
 
 import (
+	"context"
 	"fmt"
 	"github-action/pkg"
 )
 
 func GetContainerIdByName(qoveryAPIClient pkg.QoveryAPIClient, environmentId string, name string) (string, error) {
-	containers, err := qoveryAPIClient.ListContainers(environmentId)
+	return GetContainerIdByNameWithContext(context.Background(), qoveryAPIClient, environmentId, name)
+}
+
+func GetContainerIdByNameWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, name string) (string, error) {
+	containers, err := qoveryAPIClient.ListContainersWithContext(ctx, environmentId)
 	if err != nil {
 		return "", err
 	}
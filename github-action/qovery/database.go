@@ -1,12 +1,17 @@
 package qovery
 
 import (
+	"context"
 	"fmt"
 	"github-action/pkg"
 )
 
 func GetDatabaseIdByName(qoveryAPIClient pkg.QoveryAPIClient, environmentId string, name string) (string, error) {
-	databases, err := qoveryAPIClient.ListDatabases(environmentId)
+	return GetDatabaseIdByNameWithContext(context.Background(), qoveryAPIClient, environmentId, name)
+}
+
+func GetDatabaseIdByNameWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, name string) (string, error) {
+	databases, err := qoveryAPIClient.ListDatabasesWithContext(ctx, environmentId)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,218 @@
+package qovery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github-action/pkg"
+)
+
+// DeployOptions bounds how long a deployment is waited for, and tells which services are worth deploying.
+// A zero timeout means no limit other than the one of the context given to the deploy function.
+type DeployOptions struct {
+	// ReadyTimeout is the maximum wait for the environment to accept a deployment
+	ReadyTimeout time.Duration
+	// DeploymentTimeout is the maximum wait for the deployment to complete once launched
+	DeploymentTimeout time.Duration
+	// PollInterval is the delay between two status checks
+	PollInterval time.Duration
+	// StaleStateDelay is how long after its launch a service reporting a final state without having been seen queued or in progress
+	// is still considered to report the state it had before the deployment
+	StaleStateDelay time.Duration
+	// SkipUpToDate leaves out of DeployWithContext the applications and containers already running the requested commit or image tag
+	SkipUpToDate bool
+	// Logger receives the deployment progress, DefaultLogger when nil
+	Logger Logger
+}
+
+var DefaultDeployOptions = DeployOptions{
+	ReadyTimeout:      30 * time.Minute,
+	DeploymentTimeout: time.Hour,
+	PollInterval:      10 * time.Second,
+	StaleStateDelay:   time.Minute,
+}
+
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// stuckQueuedWarningDelay is how long an environment can stay queued before a warning is raised
+const stuckQueuedWarningDelay = 5 * time.Minute
+
+func (o DeployOptions) logger() Logger {
+	if o.Logger == nil {
+		return DefaultLogger
+	}
+
+	return o.Logger
+}
+
+// waitForEnvironmentReady waits, within opts.ReadyTimeout, for the environment to be in a state accepting a deployment
+func waitForEnvironmentReady(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, opts DeployOptions) error {
+	logger := opts.logger()
+	readyCtx, cancelReady := withOptionalTimeout(ctx, opts.ReadyTimeout)
+	defer cancelReady()
+
+	logger.Group("Waiting for environment to accept the deployment")
+	defer logger.EndGroup()
+
+	// Checking deployment is not QUEUED or DEPLOYING already
+	// if so, wait for it to be ready
+	var queuedSince time.Time
+	stuckWarningRaised := false
+	for readyCtx.Err() == nil {
+		status, err := qoveryAPIClient.GetEnvironmentStatusWithContext(readyCtx, environmentId)
+		if err != nil {
+			logger.Warningf("error while trying to get environment status: %s", err)
+			if err := pkg.SleepWithContext(readyCtx, opts.PollInterval); err != nil {
+				break
+			}
+			continue
+		}
+
+		warnUnknownState(logger, status.State)
+		if status.State.AcceptsDeployment() {
+			logger.Printf("Environment can accept deploy, state: %s", status.State)
+			return nil
+		}
+
+		logger.Printf("Environment cannot accept deploy yet, state: %s", status.State)
+
+		if strings.HasSuffix(string(status.State), "QUEUED") {
+			if queuedSince.IsZero() {
+				queuedSince = time.Now()
+			}
+			if !stuckWarningRaised && time.Since(queuedSince) >= stuckQueuedWarningDelay {
+				logger.Warningf("Environment %s has been queued for %s, state: %s", environmentId, time.Since(queuedSince).Round(time.Second), status.State)
+				stuckWarningRaised = true
+			}
+		} else {
+			queuedSince = time.Time{}
+		}
+
+		if err := pkg.SleepWithContext(readyCtx, opts.PollInterval); err != nil {
+			break
+		}
+	}
+
+	return readyCtx.Err()
+}
+
+// DeployWithContext deploys the databases first, and once they are deployed the applications and containers.
+// The whole sequence is reported as a single result, services not deployed because of an earlier failure being left in an unknown state.
+func DeployWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, databaseIds []string, services pkg.ServicesDeployment, opts DeployOptions) (*DeploymentResult, error) {
+	result := &DeploymentResult{
+		EnvironmentID:    environmentId,
+		EnvironmentState: string(pkg.EnvStatusUnknown),
+	}
+	deployServices := len(services.Applications) > 0 || len(services.Containers) > 0
+
+	var skipped []ServiceResult
+	if opts.SkipUpToDate && deployServices {
+		services, skipped = skipUpToDateServices(ctx, qoveryAPIClient, services, opts)
+		// skipped services are reported along with the deployed ones, whatever the outcome
+		defer func() { result.Services = append(result.Services, skipped...) }()
+		deployServices = len(services.Applications) > 0 || len(services.Containers) > 0
+	}
+
+	if len(databaseIds) == 0 && !deployServices {
+		// environment state is only informative, nothing being deployed
+		if status, err := qoveryAPIClient.GetEnvironmentStatusWithContext(ctx, environmentId); err == nil {
+			result.EnvironmentState = string(status.State)
+		}
+		return result, nil
+	}
+
+	if len(databaseIds) > 0 {
+		dbResult, err := DeployDatabasesWithContext(ctx, qoveryAPIClient, databaseIds, environmentId, opts)
+		result.Append(dbResult)
+		if err != nil {
+			if deployServices {
+				result.Services = append(result.Services, pendingServicesResult(environmentId, services).Services...)
+				err = fmt.Errorf("%w, applications and containers have not been deployed", err)
+			}
+			return result, err
+		}
+	}
+
+	if !deployServices {
+		return result, nil
+	}
+
+	servicesResult, err := DeployServicesWithContext(ctx, qoveryAPIClient, environmentId, services, opts)
+	result.Append(servicesResult)
+	return result, err
+}
+
+// skipUpToDateServices drops from services the applications and containers already running the requested commit or image tag,
+// returning the remaining services and the results of the skipped ones.
+// A service whose deployed version can't be checked is deployed.
+func skipUpToDateServices(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, services pkg.ServicesDeployment, opts DeployOptions) (pkg.ServicesDeployment, []ServiceResult) {
+	logger := opts.logger()
+
+	upToDate := map[string]ServiceResult{}
+	for _, service := range newPlannedServices(nil, services) {
+		if err := planService(ctx, qoveryAPIClient, &service); err != nil {
+			logger.Warningf("Can't tell whether %s %s is up to date, deploying it: %s", service.Type, service.ID, err)
+			continue
+		}
+		if service.UpToDate() {
+			service.Skipped = true
+			upToDate[service.ID] = service.ServiceResult
+		}
+	}
+
+	remaining := pkg.ServicesDeployment{
+		Applications: make([]pkg.ApplicationDeployment, 0, len(services.Applications)),
+		Containers:   make([]pkg.ContainerDeployment, 0, len(services.Containers)),
+	}
+	var skipped []ServiceResult
+	for _, app := range services.Applications {
+		if service, ok := upToDate[app.ApplicationId]; ok {
+			skipped = append(skipped, service)
+			continue
+		}
+		remaining.Applications = append(remaining.Applications, app)
+	}
+	for _, cont := range services.Containers {
+		if service, ok := upToDate[cont.Id]; ok {
+			skipped = append(skipped, service)
+			continue
+		}
+		remaining.Containers = append(remaining.Containers, cont)
+	}
+
+	for _, service := range skipped {
+		logger.Printf("⏭️ %s %s already runs %s, skipped", displayType(service.Type), service.ID, service.RequestedVersion)
+	}
+
+	return remaining, skipped
+}
+
+// isDeploymentOver tells whether a service or an environment reached the final state of its deployment or action,
+// rather than still reporting the final state of a former one
+func isDeploymentOver(state pkg.State, progressed bool, startedAt time.Time, opts DeployOptions) bool {
+	return state.IsTerminal() && (progressed || time.Since(startedAt) >= opts.StaleStateDelay)
+}
+
+// reportedUnknownStates holds the unknown states already warned about, so each of them is reported once
+var reportedUnknownStates sync.Map
+
+// warnUnknownState raises a warning the first time the API reports a state this action doesn't know about,
+// such a state being classified from its name only.
+func warnUnknownState(logger Logger, state pkg.State) {
+	if state.IsKnown() {
+		return
+	}
+
+	if _, reported := reportedUnknownStates.LoadOrStore(fmt.Sprint(state), true); !reported {
+		logger.Warningf("Unknown state %s reported by the Qovery API, this action may need to be updated", state)
+	}
+}
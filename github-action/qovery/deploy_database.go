@@ -1,101 +1,168 @@
 package qovery
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github-action/pkg"
 )
 
-func DeployDatabase(qoveryAPIClient pkg.QoveryAPIClient, databaseId string, qoveryEnvironmentId string) error {
-	timeout := time.Hour * 24 // high timeout we should never reach, API wil timeout before
+func DeployDatabase(qoveryAPIClient pkg.QoveryAPIClient, databaseId string, environmentId string) error {
+	_, err := DeployDatabaseWithContext(context.Background(), qoveryAPIClient, databaseId, environmentId, DefaultDeployOptions)
+	return err
+}
 
-	// Checking deployment is not QUEUED or DEPLOYING already
-	// if so, wait for it to be ready
-	stateIsOk := false
-	var status *pkg.EnvironmentStatus
-	for start := time.Now(); time.Since(start) < timeout; {
-		status, err := qoveryAPIClient.GetEnvironmentStatus(qoveryEnvironmentId)
-		if err != nil {
-			fmt.Printf("error while trying to get environment status: %s\n", err)
-			time.Sleep(10 * time.Second)
-			continue
-		}
+func DeployDatabaseWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, databaseId string, environmentId string, opts DeployOptions) (*DeploymentResult, error) {
+	return DeployDatabasesWithContext(ctx, qoveryAPIClient, []string{databaseId}, environmentId, opts)
+}
 
-		// Statuses ok to start a deployment
-		if status.State == pkg.EnvStatusDeploymentError ||
-			status.State == pkg.EnvStatusStopError ||
-			status.State == pkg.EnvStatusDeployed ||
-			status.State == pkg.EnvStatusReady ||
-			status.State == pkg.EnvStatusBuildError ||
-			status.State == pkg.EnvStatusCancelled ||
-			status.State == pkg.EnvStatusRestarted ||
-			status.State == pkg.EnvStatusRestartError ||
-			status.State == pkg.EnvStatusUnknown {
-			stateIsOk = true
-			break
-		}
+// DeployDatabasesWithContext deploys the databases concurrently and waits for each of them to complete, tracking their own status.
+// Other services of the environment being deployed meanwhile doesn't prevent databases from being deployed.
+func DeployDatabasesWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, databaseIds []string, environmentId string, opts DeployOptions) (*DeploymentResult, error) {
+	result := &DeploymentResult{
+		EnvironmentID:    environmentId,
+		EnvironmentState: string(pkg.EnvStatusUnknown),
+	}
+	for _, databaseId := range databaseIds {
+		result.Services = append(result.Services, ServiceResult{
+			Type:  ServiceTypeDatabase,
+			ID:    databaseId,
+			State: string(pkg.DbStatusUnknown),
+		})
+	}
 
-		fmt.Printf("Environment cannot accept deploy yet, state: %s\n", status.State)
+	logger := opts.logger()
+
+	// Launching deployments, waiting for each of them to be OK or ERRORED
+	result.StartedAt = time.Now()
+	logger.Group("Deployment in progress")
+	errs := make([]error, len(result.Services))
+	var wg sync.WaitGroup
+	for i := range result.Services {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = deployDatabase(ctx, qoveryAPIClient, &result.Services[i], opts)
+		}(i)
+	}
+	wg.Wait()
+	logger.EndGroup()
+	result.FinishedAt = time.Now()
+
+	// environment state is only informative, other services may still be deploying
+	if status, err := qoveryAPIClient.GetEnvironmentStatusWithContext(ctx, environmentId); err == nil {
+		result.EnvironmentState = string(status.State)
+	}
+
+	logger.Printf("\n####################################")
+	logger.Printf("ENVIRONMENT STATUS: %s\n", result.EnvironmentState)
 
-		time.Sleep(10 * time.Second)
+	// print database status
+	var failed []string
+	var firstErr error
+	for i, service := range result.Services {
+		if errs[i] != nil {
+			logger.Warningf("%s", errs[i])
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+		}
+
+		if service.Deployed {
+			logger.Printf("✅ Database %s state: %s", service.ID, service.State)
+		} else if service.state().IsError() {
+			logger.Errorf("Qovery database deployment failed", "❌ Database %s state: %s", service.ID, service.State)
+		} else {
+			logger.Printf("❔ Database %s state: %s", service.ID, service.State)
+		}
+		if !service.Deployed {
+			failed = append(failed, service.ID)
+		}
 	}
+	logger.Printf("\n####################################")
 
-	// Environment state is not valid even after timeout, cannot deploy the database
-	if !stateIsOk {
-		return fmt.Errorf("error: database cannot be deployed, environment status is : %s", status.State)
+	if firstErr != nil {
+		return result, firstErr
+	}
+	if len(failed) > 0 {
+		return result, fmt.Errorf("error: database(s) %s have not been deployed successfully", strings.Join(failed, ", "))
 	}
+	return result, nil
+}
 
-	// Launching deployment
-	err := qoveryAPIClient.DeployDatabase(pkg.Database{ID: databaseId})
-	if err != nil {
-		return fmt.Errorf("error while trying to deploy database: %s", err)
+// deployDatabase launches the deployment of a database once it accepts one, within opts.ReadyTimeout,
+// then waits for the database to reach a final state, within opts.DeploymentTimeout, filling its result.
+func deployDatabase(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, service *ServiceResult, opts DeployOptions) error {
+	if err := launchDatabaseDeployment(ctx, qoveryAPIClient, service.ID, opts); err != nil {
+		return err
 	}
+	service.StartedAt = time.Now()
+
+	logger := opts.logger()
+	deploymentCtx, cancelDeployment := withOptionalTimeout(ctx, opts.DeploymentTimeout)
+	defer cancelDeployment()
 
-	// Waiting for deployment to be OK or ERRORED with a timeout
-	lastEnvStatus := pkg.EnvStatusUnknown
-	for start := time.Now(); time.Since(start) < timeout; {
-		status, err := qoveryAPIClient.GetEnvironmentStatus(qoveryEnvironmentId)
+	// once seen queued or in progress, the database is known to report the state of this deployment
+	progressed := false
+	for deploymentCtx.Err() == nil {
+		status, err := qoveryAPIClient.GetDatabaseStatusWithContext(deploymentCtx, service.ID)
 		if err != nil {
-			return fmt.Errorf("⚠️ error while trying to get environment status: %s", err)
+			return fmt.Errorf("⚠️ Error while trying to get database %s status: %w", service.ID, err)
 		}
 
-		fmt.Printf("Deployment ongoing: status %s\n", status.State)
-		lastEnvStatus = string(status.State)
+		logger.Printf("Database %s deployment ongoing: status %s", service.ID, status.State)
+		service.State = string(status.State)
+		service.ServiceDeploymentStatus = status.ServiceDeploymentStatus
+		warnUnknownState(logger, status.State)
 
-		if status.State == pkg.EnvStatusDeployed || strings.HasSuffix(string(status.State), "ERROR") {
-			break
+		progressed = progressed || status.State.IsInProgress()
+		if isDeploymentOver(status.State, progressed, service.StartedAt, opts) {
+			service.Deployed = status.State == pkg.DbStatusDeployed
+			service.FinishedAt = time.Now()
+			return nil
 		}
 
-		time.Sleep(10 * time.Second)
+		if err := pkg.SleepWithContext(deploymentCtx, opts.PollInterval); err != nil {
+			break
+		}
 	}
 
-	fmt.Printf("\n####################################\n")
-	fmt.Printf("ENVIRONMENT STATUS: %s\n\n", lastEnvStatus)
+	return fmt.Errorf("error: stopped waiting for database %s deployment: %w", service.ID, deploymentCtx.Err())
+}
+
+// launchDatabaseDeployment requests the deployment of a database, waiting for a deployment of it already in progress to complete first.
+// A deployment refused because the environment is busy with other services is requested again later.
+func launchDatabaseDeployment(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, databaseId string, opts DeployOptions) error {
+	logger := opts.logger()
+	readyCtx, cancelReady := withOptionalTimeout(ctx, opts.ReadyTimeout)
+	defer cancelReady()
 
-	// print database status
-	dbStatus, dbErr := qoveryAPIClient.GetDatabaseStatus(databaseId)
-	if err != nil {
-		return fmt.Errorf("⚠️ Error while trying to get database %s status: %s", databaseId, dbErr)
-	}
+	for readyCtx.Err() == nil {
+		status, err := qoveryAPIClient.GetDatabaseStatusWithContext(readyCtx, databaseId)
+		if err != nil {
+			logger.Warningf("error while trying to get database %s status: %s", databaseId, err)
+		} else if !status.State.AcceptsDeployment() {
+			logger.Printf("Database %s cannot accept deploy yet, state: %s", databaseId, status.State)
+		} else {
+			err := qoveryAPIClient.DeployDatabaseWithContext(readyCtx, pkg.Database{ID: databaseId})
+			var apiErr *pkg.APIError
+			if errors.As(err, &apiErr) && apiErr.IsConflict() {
+				logger.Printf("Database %s cannot be deployed yet, environment is busy: %s", databaseId, apiErr.Message)
+			} else if err != nil {
+				return fmt.Errorf("error while trying to deploy database %s: %w", databaseId, err)
+			} else {
+				return nil
+			}
+		}
 
-	dbSuccessFullyDeployed := true
-	icon := ""
-	if status.State == pkg.DbStatusDeployed {
-		icon = "✅"
-	} else if strings.HasSuffix(string(dbStatus.State), "ERROR") {
-		dbSuccessFullyDeployed = false
-		icon = "❌"
-	} else {
-		dbSuccessFullyDeployed = false
-		icon = "❔"
+		if err := pkg.SleepWithContext(readyCtx, opts.PollInterval); err != nil {
+			break
+		}
 	}
-	fmt.Printf("%s Database %s state: %s\n", icon, databaseId, dbStatus.State)
-	fmt.Printf("\n####################################")
 
-	if !dbSuccessFullyDeployed {
-		return fmt.Errorf("error: database have not been deployed successfully")
-	}
-	return nil
+	return fmt.Errorf("error: database %s cannot be deployed, it is not ready to accept a deployment: %w", databaseId, readyCtx.Err())
 }
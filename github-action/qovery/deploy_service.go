@@ -1,123 +1,146 @@
 package qovery
 
 import (
+	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github-action/pkg"
 )
 
 func DeployServices(qoveryAPIClient pkg.QoveryAPIClient, environmentId string, services pkg.ServicesDeployment) error {
-	timeout := time.Hour * 24 // high timeout we should never reach, API wil timeout before
-
-	// Checking deployment is not QUEUED or DEPLOYING already
-	// if so, wait for it to be ready
-	stateIsOk := false
-	var status *pkg.EnvironmentStatus
-	for start := time.Now(); time.Since(start) < timeout; {
-		status, err := qoveryAPIClient.GetEnvironmentStatus(environmentId)
-		if err != nil {
-			fmt.Printf("error while trying to get environment status: %s\n", err)
-			time.Sleep(10 * time.Second)
-			continue
-		}
-
-		// Statuses ok to start a deployment
-		if status.State == pkg.EnvStatusDeploymentError ||
-			status.State == pkg.EnvStatusStopError ||
-			status.State == pkg.EnvStatusDeployed ||
-			status.State == pkg.EnvStatusReady ||
-			status.State == pkg.EnvStatusCancelled ||
-			status.State == pkg.EnvStatusRestarted ||
-			status.State == pkg.EnvStatusRestartError ||
-			status.State == pkg.EnvStatusBuildError ||
-			status.State == pkg.EnvStatusUnknown {
-			stateIsOk = true
-			break
-		}
-
-		fmt.Printf("Environment cannot accept deploy yet, state: %s\n", status.State)
+	_, err := DeployServicesWithContext(context.Background(), qoveryAPIClient, environmentId, services, DefaultDeployOptions)
+	return err
+}
 
-		time.Sleep(10 * time.Second)
-	}
+func DeployServicesWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, services pkg.ServicesDeployment, opts DeployOptions) (*DeploymentResult, error) {
+	result := pendingServicesResult(environmentId, services)
+	logger := opts.logger()
 
-	// Environment state is not valid even after timeout, cannot deploy the application
-	if !stateIsOk {
-		return fmt.Errorf("error: services cannot be deployed, environment status is : %s", status.State)
+	// Environment state is not valid even after timeout, cannot deploy
+	if err := waitForEnvironmentReady(ctx, qoveryAPIClient, environmentId, opts); err != nil {
+		return result, fmt.Errorf("error: services cannot be deployed, environment is not ready to accept a deployment: %w", err)
 	}
 
 	// Launching deployment
-	err := qoveryAPIClient.DeployServices(environmentId, services)
+	result.StartedAt = time.Now()
+	err := qoveryAPIClient.DeployServicesWithContext(ctx, environmentId, services)
 	if err != nil {
-		return fmt.Errorf("error while trying to deploy services: %s", err)
+		return result, fmt.Errorf("error while trying to deploy services: %w", err)
+	}
+	for i := range result.Services {
+		result.Services[i].StartedAt = time.Now()
 	}
 
 	// Waiting for deployment to be OK or ERRORED with a timeout
-	lastEnvStatus := pkg.EnvStatusUnknown
-	for start := time.Now(); time.Since(start) < timeout; {
-		status, err := qoveryAPIClient.GetEnvironmentStatus(environmentId)
-		if err != nil {
-			return fmt.Errorf("⚠️ Error while trying to get environment status: %s", err)
-		}
+	deploymentCtx, cancelDeployment := withOptionalTimeout(ctx, opts.DeploymentTimeout)
+	defer cancelDeployment()
 
-		fmt.Printf("Deployment ongoing: status %s\n", status.State)
-		lastEnvStatus = string(status.State)
-
-		if status.State == pkg.EnvStatusDeployed || strings.HasSuffix(string(status.State), "ERROR") {
-			break
-		}
-
-		time.Sleep(10 * time.Second)
+	logger.Group("Deployment in progress")
+	err = waitForServices(deploymentCtx, qoveryAPIClient, result, opts)
+	logger.EndGroup()
+	if err != nil {
+		return result, err
 	}
 
-	fmt.Printf("\n####################################\n")
-	fmt.Printf("ENVIRONMENT STATUS: %s\n\n", lastEnvStatus)
-
-	// print application status
-	appSuccessFullyDeployed := true
-	for _, app := range services.Applications {
-		status, err := qoveryAPIClient.GetApplicationStatus(app.ApplicationId)
-		if err != nil {
-			fmt.Errorf("⚠️ Error while trying to get application %s status: %s", app.ApplicationId, err)
-		}
+	// environment state is only informative, other services may be deploying or failing meanwhile
+	if status, err := qoveryAPIClient.GetEnvironmentStatusWithContext(ctx, environmentId); err == nil {
+		result.EnvironmentState = string(status.State)
+	}
 
-		icon := ""
-		if status.State == pkg.AppStatusDeployed {
-			icon = "✅"
-		} else if strings.HasSuffix(string(status.State), "ERROR") {
-			icon = "❌"
-			appSuccessFullyDeployed = false
+	logger.Printf("\n####################################")
+	logger.Printf("ENVIRONMENT STATUS: %s\n", result.EnvironmentState)
+
+	// print application and container status
+	successFullyDeployed := true
+	for _, service := range result.Services {
+		label := displayType(service.Type)
+		if service.Deployed {
+			logger.Printf("✅ %s %s state: %s", label, service.ID, service.State)
+		} else if service.state().IsError() {
+			logger.Errorf(fmt.Sprintf("Qovery %s deployment failed", service.Type), "❌ %s %s state: %s", label, service.ID, service.State)
 		} else {
-			icon = "❔"
+			logger.Printf("❔ %s %s state: %s", label, service.ID, service.State)
 		}
-		fmt.Printf("%s Application %s state: %s\n", icon, app.ApplicationId, status.State)
+		successFullyDeployed = successFullyDeployed && service.Deployed
 	}
 
-	// print container status
-	containerSuccessFullyDeployed := true
+	logger.Printf("\n####################################")
+
+	if !successFullyDeployed {
+		result.FinishedAt = time.Now()
+		return result, fmt.Errorf("error: some application(s) and/or container(s) have not been deployed successfully")
+	}
+	result.FinishedAt = time.Now()
+	return result, nil
+}
+
+// pendingServicesResult returns the result of a services deployment not started yet, with the requested versions
+func pendingServicesResult(environmentId string, services pkg.ServicesDeployment) *DeploymentResult {
+	result := &DeploymentResult{
+		EnvironmentID:    environmentId,
+		EnvironmentState: string(pkg.EnvStatusUnknown),
+	}
+	for _, app := range services.Applications {
+		result.Services = append(result.Services, ServiceResult{
+			Type:             ServiceTypeApplication,
+			ID:               app.ApplicationId,
+			RequestedVersion: app.GitCommitId,
+			State:            string(pkg.AppStatusUnknown),
+		})
+	}
 	for _, cont := range services.Containers {
-		status, err := qoveryAPIClient.GetContainerStatus(cont.Id)
-		if err != nil {
-			fmt.Errorf("⚠️ Error while trying to get container %s status: %s", cont.Id, err)
+		result.Services = append(result.Services, ServiceResult{
+			Type:             ServiceTypeContainer,
+			ID:               cont.Id,
+			RequestedVersion: cont.ImageTag,
+			State:            string(pkg.ContStatusUnknown),
+		})
+	}
+
+	return result
+}
+
+// waitForServices polls the status of every service of the result until all of them reach a final state
+func waitForServices(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, result *DeploymentResult, opts DeployOptions) error {
+	logger := opts.logger()
+
+	// a service seen queued or in progress is known to report the state of this deployment
+	progressed := make([]bool, len(result.Services))
+	pending := len(result.Services)
+	for pending > 0 {
+		for i := range result.Services {
+			service := &result.Services[i]
+			if !service.FinishedAt.IsZero() {
+				continue
+			}
+
+			state, err := GetServiceStateWithContext(ctx, qoveryAPIClient, service)
+			if err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				return fmt.Errorf("⚠️ Error while trying to get %s %s status: %w", service.Type, service.ID, err)
+			}
+			logger.Printf("%s %s deployment ongoing: status %s", displayType(service.Type), service.ID, service.State)
+			warnUnknownState(logger, state)
+
+			progressed[i] = progressed[i] || state.IsInProgress()
+			if isDeploymentOver(state, progressed[i], service.StartedAt, opts) {
+				service.FinishedAt = time.Now()
+				pending--
+			} else {
+				service.Deployed = false
+			}
 		}
 
-		icon := ""
-		if status.State == pkg.AppStatusDeployed {
-			icon = "✅"
-		} else if strings.HasSuffix(string(status.State), "ERROR") {
-			icon = "❌"
-			containerSuccessFullyDeployed = false
-		} else {
-			icon = "❔"
+		if pending == 0 {
+			break
+		}
+		if err := pkg.SleepWithContext(ctx, opts.PollInterval); err != nil {
+			return fmt.Errorf("error: stopped waiting for services deployment: %w", err)
 		}
-		fmt.Printf("%s Container %s state: %s\n", icon, cont.Id, status.State)
 	}
 
-	fmt.Printf("\n####################################")
-
-	if !appSuccessFullyDeployed || !containerSuccessFullyDeployed {
-		return fmt.Errorf("error: some application(s) and/or container(s) have not been deployed successfully")
-	}
 	return nil
 }
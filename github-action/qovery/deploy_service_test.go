@@ -0,0 +1,115 @@
+package qovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github-action/pkg"
+	"github-action/qoverytest"
+)
+
+var testDeployOptions = DeployOptions{
+	ReadyTimeout:      5 * time.Second,
+	DeploymentTimeout: 5 * time.Second,
+	PollInterval:      time.Millisecond,
+	StaleStateDelay:   100 * time.Millisecond,
+}
+
+func newTestServer() *qoverytest.Server {
+	server := qoverytest.NewServer()
+	server.AddOrganization("org-id", "org")
+	server.AddProject("org-id", "project-id", "project")
+	server.AddEnvironment("project-id", "env-id", "production")
+	server.AddApplication("env-id", "app-id", "front")
+	server.AddContainer("env-id", "container-id", "worker")
+
+	return server
+}
+
+func TestDeployServices(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		name          string
+		envState      string
+		appStates     []string
+		expectedError bool
+	}{
+		{name: "deployed", envState: "DEPLOYING", appStates: []string{"QUEUED", "BUILDING", "DEPLOYING", "DEPLOYED"}},
+		{name: "deployment error", envState: "DEPLOYMENT_ERROR", appStates: []string{"BUILDING", "BUILD_ERROR"}, expectedError: true},
+		{name: "stale state first", envState: "DEPLOYMENT_ERROR", appStates: []string{"DEPLOYED", "QUEUED", "BUILDING", "BUILD_ERROR"}, expectedError: true},
+		{name: "unrelated service failure", envState: "DEPLOYMENT_ERROR", appStates: []string{"DEPLOYING", "DEPLOYED"}},
+	}
+
+	for _, tc := range testCases {
+		server := newTestServer()
+		server.SetStates("env-id", "QUEUED", "DEPLOYING", "DEPLOYED")
+		server.OnDeploy("env-id", tc.envState)
+		server.OnDeploy("app-id", tc.appStates...)
+		server.OnDeploy("container-id", "DEPLOYED")
+		services := pkg.ServicesDeployment{
+			Applications: []pkg.ApplicationDeployment{{ApplicationId: "app-id", GitCommitId: "sha"}},
+			Containers:   []pkg.ContainerDeployment{{Id: "container-id", ImageTag: "v1"}},
+		}
+
+		// execute:
+		result, err := DeployServicesWithContext(context.Background(), server.Client(), "env-id", services, testDeployOptions)
+		server.Close()
+
+		// verify:
+		if tc.expectedError != (err != nil) {
+			t.Fatalf("%s: expected error to be %v but was %v", tc.name, tc.expectedError, err)
+		}
+		if result.EnvironmentState != tc.envState {
+			t.Fatalf("%s: expected environment state %s but was %s", tc.name, tc.envState, result.EnvironmentState)
+		}
+		if states := result.ServiceStates(); states["app-id"] != tc.appStates[len(tc.appStates)-1] || states["container-id"] != "DEPLOYED" {
+			t.Fatalf("%s: unexpected service states %v", tc.name, states)
+		}
+		deployments := server.Deployments()
+		if len(deployments) != 1 || deployments[0].Services.Containers[0].ImageTag != "v1" {
+			t.Fatalf("%s: expected a single deployment of the requested services but was %+v", tc.name, deployments)
+		}
+	}
+}
+
+func TestDeployServicesEnvironmentNeverReady(t *testing.T) {
+	// setup:
+	server := newTestServer()
+	defer server.Close()
+	server.SetStates("env-id", "DEPLOYING")
+	opts := testDeployOptions
+	opts.ReadyTimeout = 50 * time.Millisecond
+
+	// execute:
+	_, err := DeployServicesWithContext(context.Background(), server.Client(), "env-id", pkg.ServicesDeployment{}, opts)
+
+	// verify:
+	if err == nil {
+		t.Fatalf("expected an error when the environment never accepts the deployment")
+	}
+	if len(server.Deployments()) != 0 {
+		t.Fatalf("expected no deployment to be launched but was %+v", server.Deployments())
+	}
+}
+
+func TestResolveServiceKeys(t *testing.T) {
+	// setup:
+	server := newTestServer()
+	defer server.Close()
+	server.AddApplication("env-id", "back-id", "back")
+
+	// execute:
+	resolved, err := ResolveServiceKeysWithContext(context.Background(), server.Client(), "env-id", ServiceTypeApplication, map[string]string{"front": "abc", "back-id": "def"})
+
+	// verify:
+	if err != nil {
+		t.Fatalf("expected no error but was %s", err)
+	}
+	if len(resolved) != 2 || resolved["app-id"] != "abc" || resolved["back-id"] != "def" {
+		t.Errorf("unexpected resolved values %v", resolved)
+	}
+	if _, err := ResolveServiceKeysWithContext(context.Background(), server.Client(), "env-id", ServiceTypeApplication, map[string]string{"worker": "abc"}); err == nil {
+		t.Errorf("expected an error as worker is a container")
+	}
+}
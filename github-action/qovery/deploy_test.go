@@ -0,0 +1,174 @@
+package qovery
+
+import (
+	"context"
+	"testing"
+
+	"github-action/pkg"
+)
+
+func TestDeployDatabaseThenServices(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		name                string
+		dbStates            []string
+		expectedDeployments int
+		expectedAppDeployed bool
+		expectedError       bool
+	}{
+		{name: "database deployed", dbStates: []string{"DEPLOYED"}, expectedDeployments: 2, expectedAppDeployed: true},
+		{name: "database failed", dbStates: []string{"DEPLOYMENT_ERROR"}, expectedDeployments: 1, expectedError: true},
+		{name: "stale database state first", dbStates: []string{"DEPLOYED", "DEPLOYING", "DEPLOYMENT_ERROR"}, expectedDeployments: 1, expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		server := newTestServer()
+		server.AddDatabase("env-id", "db-id", "postgres")
+		server.OnDeploy("db-id", tc.dbStates...)
+		server.OnDeploy("app-id", "DEPLOYED")
+		services := pkg.ServicesDeployment{
+			Applications: []pkg.ApplicationDeployment{{ApplicationId: "app-id", GitCommitId: "sha"}},
+		}
+
+		// execute:
+		result, err := DeployWithContext(context.Background(), server.Client(), "env-id", []string{"db-id"}, services, testDeployOptions)
+		deployments := server.Deployments()
+		server.Close()
+
+		// verify:
+		if tc.expectedError != (err != nil) {
+			t.Fatalf("%s: expected error to be %v but was %v", tc.name, tc.expectedError, err)
+		}
+		if len(deployments) != tc.expectedDeployments || deployments[0].DatabaseID != "db-id" {
+			t.Errorf("%s: expected %d deployments, database first, but was %+v", tc.name, tc.expectedDeployments, deployments)
+		}
+		if len(result.Services) != 2 || result.Services[0].ID != "db-id" || result.Services[1].ID != "app-id" {
+			t.Fatalf("%s: expected database and application results but was %+v", tc.name, result.Services)
+		}
+		if result.Services[1].Deployed != tc.expectedAppDeployed {
+			t.Errorf("%s: expected application deployed to be %v", tc.name, tc.expectedAppDeployed)
+		}
+		if app := result.Services[1]; tc.expectedAppDeployed && app.StartedAt.Before(result.Services[0].FinishedAt) {
+			t.Errorf("%s: expected application duration to start once the database is deployed but was %+v", tc.name, app)
+		}
+	}
+}
+
+func TestDeployDatabases(t *testing.T) {
+	// setup:
+	server := newTestServer()
+	defer server.Close()
+	server.AddDatabase("env-id", "postgres-id", "postgres")
+	server.AddDatabase("env-id", "redis-id", "redis")
+	server.OnDeploy("postgres-id", "DEPLOYED")
+	server.OnDeploy("redis-id", "DEPLOYMENT_ERROR")
+
+	// execute:
+	result, err := DeployDatabasesWithContext(context.Background(), server.Client(), []string{"postgres-id", "redis-id"}, "env-id", testDeployOptions)
+
+	// verify:
+	if err == nil {
+		t.Fatalf("expected an error as redis failed to deploy")
+	}
+	if len(server.Deployments()) != 2 {
+		t.Errorf("expected both databases to be deployed but was %+v", server.Deployments())
+	}
+	postgres, redis := result.Service("postgres-id"), result.Service("redis-id")
+	if !postgres.Deployed || postgres.State != "DEPLOYED" {
+		t.Errorf("expected postgres to be deployed but was %+v", postgres)
+	}
+	if redis.Deployed || redis.State != "DEPLOYMENT_ERROR" {
+		t.Errorf("expected redis deployment to fail but was %+v", redis)
+	}
+}
+
+func TestDeployDatabaseWhileEnvironmentIsBusy(t *testing.T) {
+	// setup:
+	server := newTestServer()
+	defer server.Close()
+	server.AddDatabase("env-id", "db-id", "postgres")
+	server.SetStates("env-id", "DEPLOYING")
+	server.OnDeploy("env-id", "DEPLOYING")
+	server.SetStates("db-id", "QUEUED", "DEPLOYED")
+	server.OnDeploy("db-id", "DEPLOYING", "DEPLOYMENT_ERROR")
+	server.FailNext("POST", "/database/db-id/deploy", 409)
+
+	// execute:
+	result, err := DeployDatabaseWithContext(context.Background(), server.Client(), "db-id", "env-id", testDeployOptions)
+
+	// verify:
+	if err == nil {
+		t.Fatalf("expected an error as the database failed to deploy")
+	}
+	if len(server.Deployments()) != 1 {
+		t.Errorf("expected the database to be deployed once the conflict is gone but was %+v", server.Deployments())
+	}
+	if service := result.Service("db-id"); service.State != "DEPLOYMENT_ERROR" || service.Deployed {
+		t.Errorf("expected the database failure to be reported but was %+v", service)
+	}
+	if result.EnvironmentState != "DEPLOYING" {
+		t.Errorf("expected environment state DEPLOYING but was %s", result.EnvironmentState)
+	}
+}
+
+func TestDeploySkipUpToDate(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		name                string
+		appState            string
+		deployedCommit      string
+		deployedTag         string
+		failedLookup        bool
+		expectedDeployed    []string
+		expectedSkipped     []string
+		expectedDeployments int
+	}{
+		{name: "all up to date", appState: "DEPLOYED", deployedCommit: "sha", deployedTag: "v1", expectedSkipped: []string{"app-id", "container-id"}},
+		{name: "new tag", appState: "DEPLOYED", deployedCommit: "sha", deployedTag: "v0", expectedDeployed: []string{"container-id"}, expectedSkipped: []string{"app-id"}, expectedDeployments: 1},
+		{name: "container lookup failure", appState: "DEPLOYED", deployedCommit: "sha", deployedTag: "v1", failedLookup: true, expectedDeployed: []string{"container-id"}, expectedSkipped: []string{"app-id"}, expectedDeployments: 1},
+		{name: "same commit but stopped", appState: "STOPPED", deployedCommit: "sha", deployedTag: "v1", expectedDeployed: []string{"app-id"}, expectedSkipped: []string{"container-id"}, expectedDeployments: 1},
+	}
+
+	for _, tc := range testCases {
+		server := newTestServer()
+		server.SetStates("app-id", tc.appState)
+		server.SetStates("container-id", "DEPLOYED")
+		server.SetDeployedVersion("app-id", "main", tc.deployedCommit)
+		server.SetDeployedVersion("container-id", "", tc.deployedTag)
+		if tc.failedLookup {
+			server.FailNext("GET", "/container/container-id", 500)
+		}
+		services := pkg.ServicesDeployment{
+			Applications: []pkg.ApplicationDeployment{{ApplicationId: "app-id", GitCommitId: "sha"}},
+			Containers:   []pkg.ContainerDeployment{{Id: "container-id", ImageTag: "v1"}},
+		}
+		opts := testDeployOptions
+		opts.SkipUpToDate = true
+
+		// execute:
+		result, err := DeployWithContext(context.Background(), server.Client(), "env-id", nil, services, opts)
+		deployments := server.Deployments()
+		server.Close()
+
+		// verify:
+		if err != nil {
+			t.Fatalf("%s: expected no error but was %v", tc.name, err)
+		}
+		if len(deployments) != tc.expectedDeployments {
+			t.Fatalf("%s: expected %d deployments but was %+v", tc.name, tc.expectedDeployments, deployments)
+		}
+		if len(deployments) > 0 && len(deployments[0].Services.Applications)+len(deployments[0].Services.Containers) != len(tc.expectedDeployed) {
+			t.Errorf("%s: expected only %v to be deployed but was %+v", tc.name, tc.expectedDeployed, deployments[0].Services)
+		}
+		for _, id := range tc.expectedDeployed {
+			if service := result.Service(id); service == nil || service.Skipped || !service.Deployed {
+				t.Errorf("%s: expected %s to be deployed but was %+v", tc.name, id, service)
+			}
+		}
+		for _, id := range tc.expectedSkipped {
+			if service := result.Service(id); service == nil || !service.Skipped {
+				t.Errorf("%s: expected %s to be reported as skipped but was %+v", tc.name, id, service)
+			}
+		}
+	}
+}
@@ -1,12 +1,17 @@
 package qovery
 
 import (
+	"context"
 	"fmt"
 	"github-action/pkg"
 )
 
 func GetEnvironmentIdByName(qoveryAPIClient pkg.QoveryAPIClient, projectId string, name string) (string, error) {
-	environments, err := qoveryAPIClient.ListEnvironments(projectId)
+	return GetEnvironmentIdByNameWithContext(context.Background(), qoveryAPIClient, projectId, name)
+}
+
+func GetEnvironmentIdByNameWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, projectId string, name string) (string, error) {
+	environments, err := qoveryAPIClient.ListEnvironmentsWithContext(ctx, projectId)
 	if err != nil {
 		return "", err
 	}
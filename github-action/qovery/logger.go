@@ -0,0 +1,37 @@
+package qovery
+
+import "fmt"
+
+// Logger receives the progress of deployments
+type Logger interface {
+	Printf(format string, args ...interface{})
+	Warningf(format string, args ...interface{})
+	// Errorf reports a failure, title being a short description of what failed
+	Errorf(title string, format string, args ...interface{})
+	// Group starts a section of related lines, ended by EndGroup
+	Group(name string)
+	EndGroup()
+}
+
+// DefaultLogger prints plain lines to stdout
+var DefaultLogger Logger = stdoutLogger{}
+
+type stdoutLogger struct{}
+
+func (stdoutLogger) Printf(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+}
+
+func (stdoutLogger) Warningf(format string, args ...interface{}) {
+	fmt.Printf("⚠️ "+format+"\n", args...)
+}
+
+func (stdoutLogger) Errorf(title string, format string, args ...interface{}) {
+	fmt.Printf("❌ "+title+": "+format+"\n", args...)
+}
+
+func (stdoutLogger) Group(name string) {
+	fmt.Printf("\n%s\n", name)
+}
+
+func (stdoutLogger) EndGroup() {}
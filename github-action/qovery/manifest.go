@@ -0,0 +1,236 @@
+package qovery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github-action/pkg"
+)
+
+// DefaultManifestPath is where the deployment manifest is conventionally kept in a repository
+const DefaultManifestPath = ".qovery-deploy.yml"
+
+// Manifest describes a deployment: where to deploy, which services with which versions, and how long to wait.
+// It is read from a YAML or JSON file, e.g.
+//
+//	organization: {name: acme}
+//	project: {name: shop}
+//	environment: {name: production}
+//	applications:
+//	  - name: front
+//	    commit: 0123456789abcdef0123456789abcdef01234567
+//	containers:
+//	  - id: 3d1f3b0c-0000-0000-0000-000000000000
+//	    tag: v1.2.0
+//	databases:
+//	  - name: postgres
+//	options:
+//	  deployment-timeout: 30m
+type Manifest struct {
+	Organization *ManifestReference    `yaml:"organization"`
+	Project      *ManifestReference    `yaml:"project"`
+	Environment  *ManifestReference    `yaml:"environment"`
+	Applications []ManifestApplication `yaml:"applications"`
+	Containers   []ManifestContainer   `yaml:"containers"`
+	Databases    []ManifestReference   `yaml:"databases"`
+	Options      ManifestOptions       `yaml:"options"`
+}
+
+// ManifestReference designates a Qovery resource either by ID or by name
+type ManifestReference struct {
+	ID   string `yaml:"id"`
+	Name string `yaml:"name"`
+}
+
+type ManifestApplication struct {
+	ManifestReference `yaml:",inline"`
+	// Commit is the git commit to deploy, the head of the application branch being deployed when empty
+	Commit string `yaml:"commit"`
+}
+
+type ManifestContainer struct {
+	ManifestReference `yaml:",inline"`
+	// Tag is the image tag to deploy
+	Tag string `yaml:"tag"`
+}
+
+// ManifestOptions overrides the deploy options given as flags, nil fields leaving them untouched
+type ManifestOptions struct {
+	ReadyTimeout      *time.Duration `yaml:"ready-timeout"`
+	DeploymentTimeout *time.Duration `yaml:"deployment-timeout"`
+	PollInterval      *time.Duration `yaml:"poll-interval"`
+}
+
+// LoadManifest reads and validates the manifest file at path
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML being a superset of JSON, both are read the same way
+	manifest := Manifest{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid manifest %s: file is empty", path)
+		}
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	return &manifest, nil
+}
+
+// Validate checks the manifest describes a deployment, reporting every problem found.
+// The manifest is never checked against qovery-deploy.schema.json, only published for editors: Validate is the reference for its rules.
+func (m Manifest) Validate() error {
+	var problems []string
+
+	for field, ref := range map[string]*ManifestReference{"organization": m.Organization, "project": m.Project, "environment": m.Environment} {
+		if ref != nil {
+			problems = append(problems, ref.validate(field)...)
+		}
+	}
+
+	if len(m.Applications) == 0 && len(m.Containers) == 0 && len(m.Databases) == 0 {
+		problems = append(problems, "at least one application, container or database must be defined")
+	}
+
+	seen := map[string]string{}
+	checkDuplicate := func(field string, kind string, ref ManifestReference) {
+		key := kind + " " + ref.ID + "/" + ref.Name
+		if previous, ok := seen[key]; ok {
+			problems = append(problems, fmt.Sprintf("%s: same %s as %s", field, kind, previous))
+		}
+		seen[key] = field
+	}
+
+	for i, app := range m.Applications {
+		field := fmt.Sprintf("applications[%d]", i)
+		problems = append(problems, app.validate(field)...)
+		checkDuplicate(field, ServiceTypeApplication, app.ManifestReference)
+	}
+	for i, container := range m.Containers {
+		field := fmt.Sprintf("containers[%d]", i)
+		problems = append(problems, container.validate(field)...)
+		if strings.TrimSpace(container.Tag) == "" {
+			problems = append(problems, field+": 'tag' must be defined")
+		}
+		checkDuplicate(field, ServiceTypeContainer, container.ManifestReference)
+	}
+	for i, db := range m.Databases {
+		field := fmt.Sprintf("databases[%d]", i)
+		problems = append(problems, db.validate(field)...)
+		checkDuplicate(field, ServiceTypeDatabase, db)
+	}
+
+	for field, duration := range map[string]*time.Duration{
+		"options.ready-timeout":      m.Options.ReadyTimeout,
+		"options.deployment-timeout": m.Options.DeploymentTimeout,
+		"options.poll-interval":      m.Options.PollInterval,
+	} {
+		if duration != nil && *duration < 0 {
+			problems = append(problems, field+": must not be negative")
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	// map iteration above is random, keep the report stable
+	sort.Strings(problems)
+	return fmt.Errorf("\n- %s", strings.Join(problems, "\n- "))
+}
+
+func (r ManifestReference) validate(field string) []string {
+	id, name := strings.TrimSpace(r.ID), strings.TrimSpace(r.Name)
+	switch {
+	case id == "" && name == "":
+		return []string{field + ": either 'id' or 'name' must be defined"}
+	case id != "" && name != "":
+		return []string{field + ": only one of 'id' or 'name' must be defined"}
+	default:
+		return nil
+	}
+}
+
+// Apply overrides the deploy options with the ones set in the manifest
+func (o ManifestOptions) Apply(opts DeployOptions) DeployOptions {
+	if o.ReadyTimeout != nil {
+		opts.ReadyTimeout = *o.ReadyTimeout
+	}
+	if o.DeploymentTimeout != nil {
+		opts.DeploymentTimeout = *o.DeploymentTimeout
+	}
+	if o.PollInterval != nil {
+		opts.PollInterval = *o.PollInterval
+	}
+
+	return opts
+}
+
+// ResolveWithContext turns the services of the manifest into the deployment of the applications and containers, and the IDs of the databases.
+// Applications without a commit are deployed at defaultCommit, at the head of their branch if empty.
+func (m Manifest) ResolveWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, defaultCommit string) (pkg.ServicesDeployment, []string, error) {
+	services := pkg.ServicesDeployment{
+		Applications: make([]pkg.ApplicationDeployment, 0, len(m.Applications)),
+		Containers:   make([]pkg.ContainerDeployment, 0, len(m.Containers)),
+	}
+
+	for _, app := range m.Applications {
+		id := strings.TrimSpace(app.ID)
+		if id == "" {
+			var err error
+			if id, err = GetApplicationIdByNameWithContext(ctx, qoveryAPIClient, environmentId, strings.TrimSpace(app.Name)); err != nil {
+				return services, nil, err
+			}
+		}
+
+		commit := strings.TrimSpace(app.Commit)
+		if commit == "" {
+			commit = defaultCommit
+		}
+		services.Applications = append(services.Applications, pkg.ApplicationDeployment{ApplicationId: id, GitCommitId: commit})
+	}
+
+	for _, container := range m.Containers {
+		id := strings.TrimSpace(container.ID)
+		if id == "" {
+			var err error
+			if id, err = GetContainerIdByNameWithContext(ctx, qoveryAPIClient, environmentId, strings.TrimSpace(container.Name)); err != nil {
+				return services, nil, err
+			}
+		}
+
+		services.Containers = append(services.Containers, pkg.ContainerDeployment{Id: id, ImageTag: strings.TrimSpace(container.Tag)})
+	}
+
+	var databaseIds []string
+	for _, db := range m.Databases {
+		id := strings.TrimSpace(db.ID)
+		if id == "" {
+			var err error
+			if id, err = GetDatabaseIdByNameWithContext(ctx, qoveryAPIClient, environmentId, strings.TrimSpace(db.Name)); err != nil {
+				return services, nil, err
+			}
+		}
+
+		databaseIds = append(databaseIds, id)
+	}
+
+	return services, databaseIds, nil
+}
@@ -0,0 +1,206 @@
+package qovery
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeManifest(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadManifest(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		name          string
+		file          string
+		content       string
+		expectedError string
+	}{
+		{
+			name: "yaml",
+			file: ".qovery-deploy.yml",
+			content: `
+environment: {name: production}
+applications:
+  - name: front
+    commit: abc
+containers:
+  - id: container-id
+    tag: v1
+databases:
+  - name: postgres
+options:
+  deployment-timeout: 20m
+`,
+		},
+		{
+			name:    "json",
+			file:    "qovery-deploy.json",
+			content: `{"environment": {"name": "production"}, "applications": [{"name": "front", "commit": "abc"}], "containers": [{"id": "container-id", "tag": "v1"}], "databases": [{"name": "postgres"}], "options": {"deployment-timeout": "20m"}}`,
+		},
+		{name: "unknown field", file: "m.yml", content: "applications:\n  - name: front\n    branch: main\n", expectedError: "field branch not found"},
+		{name: "no service", file: "m.yml", content: "environment: {name: production}\n", expectedError: "at least one application, container or database must be defined"},
+		{name: "id and name", file: "m.yml", content: "databases:\n  - {id: db-id, name: postgres}\n", expectedError: "databases[0]: only one of 'id' or 'name' must be defined"},
+		{name: "missing tag", file: "m.yml", content: "containers:\n  - name: worker\n", expectedError: "containers[0]: 'tag' must be defined"},
+		{name: "duplicate", file: "m.yml", content: "applications:\n  - name: front\n  - name: front\n", expectedError: "applications[1]: same application as applications[0]"},
+		{name: "empty", file: "m.yml", content: "", expectedError: "file is empty"},
+	}
+
+	for _, tc := range testCases {
+		path := writeManifest(t, tc.file, tc.content)
+
+		// execute:
+		manifest, err := LoadManifest(path)
+
+		// verify:
+		if tc.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("%s: expected error containing %q but was %v", tc.name, tc.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected no error but was %s", tc.name, err)
+		}
+		if manifest.Environment.Name != "production" || len(manifest.Applications) != 1 || manifest.Applications[0].Commit != "abc" || manifest.Containers[0].Tag != "v1" {
+			t.Errorf("%s: unexpected manifest %+v", tc.name, manifest)
+		}
+		if opts := manifest.Options.Apply(DefaultDeployOptions); opts.DeploymentTimeout != 20*time.Minute || opts.ReadyTimeout != DefaultDeployOptions.ReadyTimeout {
+			t.Errorf("%s: unexpected options %+v", tc.name, opts)
+		}
+	}
+}
+
+func TestResolveManifest(t *testing.T) {
+	// setup:
+	server := newTestServer()
+	defer server.Close()
+	server.AddDatabase("env-id", "db-id", "postgres")
+	manifest := Manifest{
+		Applications: []ManifestApplication{{ManifestReference: ManifestReference{Name: "front"}}},
+		Containers:   []ManifestContainer{{ManifestReference: ManifestReference{Name: "worker"}, Tag: "v1"}},
+		Databases:    []ManifestReference{{Name: "postgres"}},
+	}
+
+	// execute:
+	services, databaseIds, err := manifest.ResolveWithContext(context.Background(), server.Client(), "env-id", "sha")
+
+	// verify:
+	if err != nil {
+		t.Fatalf("expected no error but was %s", err)
+	}
+	if len(services.Applications) != 1 || services.Applications[0].ApplicationId != "app-id" || services.Applications[0].GitCommitId != "sha" {
+		t.Errorf("unexpected applications %+v", services.Applications)
+	}
+	if len(services.Containers) != 1 || services.Containers[0].Id != "container-id" || services.Containers[0].ImageTag != "v1" {
+		t.Errorf("unexpected containers %+v", services.Containers)
+	}
+	if len(databaseIds) != 1 || databaseIds[0] != "db-id" {
+		t.Errorf("unexpected databases %v", databaseIds)
+	}
+}
+
+// manifestSchemaPath is the JSON schema of the manifest published for editors, the manifest types and Validate being the reference.
+// Only the properties it declares are checked against the manifest types, not its rules.
+const manifestSchemaPath = "../../qovery-deploy.schema.json"
+
+// jsonSchema is the part of a JSON schema describing the structure of an object
+type jsonSchema struct {
+	Ref                   string                `json:"$ref"`
+	Properties            map[string]jsonSchema `json:"properties"`
+	Items                 *jsonSchema           `json:"items"`
+	Required              []string              `json:"required"`
+	AdditionalProperties  *bool                 `json:"additionalProperties"`
+	UnevaluatedProperties *bool                 `json:"unevaluatedProperties"`
+	Defs                  map[string]jsonSchema `json:"$defs"`
+}
+
+// keys returns the properties of the object, including the ones of the definition it refers to
+func (s jsonSchema) keys(defs map[string]jsonSchema) []string {
+	var keys []string
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	if s.Ref != "" {
+		keys = append(keys, defs[strings.TrimPrefix(s.Ref, "#/$defs/")].keys(defs)...)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// closed tells whether the object rejects unknown properties, as the manifest decoder does
+func (s jsonSchema) closed() bool {
+	return (s.AdditionalProperties != nil && !*s.AdditionalProperties) || (s.UnevaluatedProperties != nil && !*s.UnevaluatedProperties)
+}
+
+func yamlKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		name, options, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if options == "inline" {
+			keys = append(keys, yamlKeys(t.Field(i).Type)...)
+			continue
+		}
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func TestManifestSchemaProperties(t *testing.T) {
+	// setup:
+	data, err := os.ReadFile(manifestSchemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := jsonSchema{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("invalid schema %s: %s", manifestSchemaPath, err)
+	}
+	testCases := []struct {
+		name     string
+		schema   jsonSchema
+		goType   reflect.Type
+		required []string
+	}{
+		{name: "manifest", schema: schema, goType: reflect.TypeOf(Manifest{})},
+		{name: "organization", schema: schema.Properties["organization"], goType: reflect.TypeOf(ManifestReference{})},
+		{name: "project", schema: schema.Properties["project"], goType: reflect.TypeOf(ManifestReference{})},
+		{name: "environment", schema: schema.Properties["environment"], goType: reflect.TypeOf(ManifestReference{})},
+		{name: "applications", schema: *schema.Properties["applications"].Items, goType: reflect.TypeOf(ManifestApplication{})},
+		{name: "containers", schema: *schema.Properties["containers"].Items, goType: reflect.TypeOf(ManifestContainer{}), required: []string{"tag"}},
+		{name: "databases", schema: *schema.Properties["databases"].Items, goType: reflect.TypeOf(ManifestReference{})},
+		{name: "options", schema: schema.Properties["options"], goType: reflect.TypeOf(ManifestOptions{})},
+	}
+
+	for _, tc := range testCases {
+		// execute:
+		keys := tc.schema.keys(schema.Defs)
+
+		// verify:
+		if expected := yamlKeys(tc.goType); !reflect.DeepEqual(keys, expected) {
+			t.Errorf("%s: expected schema properties %v but was %v", tc.name, expected, keys)
+		}
+		if !tc.schema.closed() {
+			t.Errorf("%s: expected schema to reject unknown properties", tc.name)
+		}
+		if !reflect.DeepEqual(tc.schema.Required, tc.required) {
+			t.Errorf("%s: expected schema required properties %v but was %v", tc.name, tc.required, tc.schema.Required)
+		}
+	}
+}
@@ -1,12 +1,17 @@
 package qovery
 
 import (
+	"context"
 	"fmt"
 	"github-action/pkg"
 )
 
 func GetOrganizationIdByName(qoveryAPIClient pkg.QoveryAPIClient, name string) (string, error) {
-	organizations, err := qoveryAPIClient.ListOrganizations()
+	return GetOrganizationIdByNameWithContext(context.Background(), qoveryAPIClient, name)
+}
+
+func GetOrganizationIdByNameWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, name string) (string, error) {
+	organizations, err := qoveryAPIClient.ListOrganizationsWithContext(ctx)
 	if err != nil {
 		return "", err
 	}
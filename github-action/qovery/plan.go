@@ -0,0 +1,199 @@
+package qovery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github-action/pkg"
+)
+
+// Plan is what a deployment would do, without deploying anything
+type Plan struct {
+	OrganizationID   string           `json:"organization_id,omitempty"`
+	ProjectID        string           `json:"project_id,omitempty"`
+	EnvironmentID    string           `json:"environment_id"`
+	EnvironmentState string           `json:"environment_state"`
+	Services         []PlannedService `json:"services"`
+}
+
+// PlannedService is the current state of a targeted service and the version it would be deployed at
+type PlannedService struct {
+	ServiceResult
+	// CurrentVersion is the commit or image tag currently deployed, empty for databases or if unknown
+	CurrentVersion string `json:"current_version,omitempty"`
+	// Branch is the git branch of an application, deployed at its head when no commit is requested
+	Branch string `json:"branch,omitempty"`
+	Change string `json:"change"`
+}
+
+// PlanWithContext resolves the current state of the targeted databases and services, and what deploying them would change.
+// Only read calls are made to the API.
+func PlanWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, databaseIds []string, services pkg.ServicesDeployment) (*Plan, error) {
+	status, err := qoveryAPIClient.GetEnvironmentStatusWithContext(ctx, environmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		EnvironmentID:    environmentId,
+		EnvironmentState: string(status.State),
+	}
+
+	names, err := ListServiceNamesWithContext(ctx, qoveryAPIClient, environmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Services, err = planServices(ctx, qoveryAPIClient, databaseIds, services)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plan.Services {
+		plan.Services[i].Name = names[plan.Services[i].ID]
+	}
+
+	return plan, nil
+}
+
+// planServices fetches the state and the deployed version of the targeted databases and services
+func planServices(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, databaseIds []string, services pkg.ServicesDeployment) ([]PlannedService, error) {
+	planned := newPlannedServices(databaseIds, services)
+	for i := range planned {
+		if err := planService(ctx, qoveryAPIClient, &planned[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return planned, nil
+}
+
+// newPlannedServices returns the targeted databases and services, with the requested versions only
+func newPlannedServices(databaseIds []string, services pkg.ServicesDeployment) []PlannedService {
+	var planned []PlannedService
+	for _, databaseId := range databaseIds {
+		planned = append(planned, PlannedService{ServiceResult: ServiceResult{Type: ServiceTypeDatabase, ID: databaseId}})
+	}
+	for _, app := range services.Applications {
+		planned = append(planned, PlannedService{ServiceResult: ServiceResult{Type: ServiceTypeApplication, ID: app.ApplicationId, RequestedVersion: app.GitCommitId}})
+	}
+	for _, cont := range services.Containers {
+		planned = append(planned, PlannedService{ServiceResult: ServiceResult{Type: ServiceTypeContainer, ID: cont.Id, RequestedVersion: cont.ImageTag}})
+	}
+
+	return planned
+}
+
+// planService fetches the deployed version and the state of a service, and what deploying it would change
+func planService(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, service *PlannedService) error {
+	switch service.Type {
+	case ServiceTypeApplication:
+		application, err := qoveryAPIClient.GetApplicationWithContext(ctx, service.ID)
+		if err != nil {
+			return fmt.Errorf("error while trying to get application %s: %w", service.ID, err)
+		}
+		service.CurrentVersion = application.GitRepository.DeployedCommitId
+		service.Branch = application.GitRepository.Branch
+	case ServiceTypeContainer:
+		container, err := qoveryAPIClient.GetContainerWithContext(ctx, service.ID)
+		if err != nil {
+			return fmt.Errorf("error while trying to get container %s: %w", service.ID, err)
+		}
+		service.CurrentVersion = container.Tag
+	}
+
+	if _, err := GetServiceStateWithContext(ctx, qoveryAPIClient, &service.ServiceResult); err != nil {
+		return fmt.Errorf("error while trying to get %s %s status: %w", service.Type, service.ID, err)
+	}
+	service.Change = service.change()
+
+	return nil
+}
+
+// UpToDate tells whether the service already runs the requested commit or image tag, with nothing else left to deploy.
+// Databases and applications deployed at the head of their branch are never considered up to date.
+func (s PlannedService) UpToDate() bool {
+	return s.Type != ServiceTypeDatabase &&
+		s.RequestedVersion != "" &&
+		s.CurrentVersion == s.RequestedVersion &&
+		s.Deployed &&
+		(s.ServiceDeploymentStatus == "" || s.ServiceDeploymentStatus == pkg.ServiceDeploymentStatusUpToDate)
+}
+
+// SkipUpToDate marks the services already up to date as skipped, as a deployment skipping them would do
+func (p *Plan) SkipUpToDate() {
+	for i := range p.Services {
+		service := &p.Services[i]
+		if service.UpToDate() {
+			service.Skipped = true
+			service.Change = fmt.Sprintf("skip, %s already running", shortVersion(service.Type, service.CurrentVersion))
+		}
+	}
+}
+
+// change describes what deploying the service would do, e.g. "abc1234 → def5678"
+func (s PlannedService) change() string {
+	switch {
+	case s.Type == ServiceTypeDatabase:
+		return "redeploy"
+	case s.RequestedVersion == "" && s.Branch != "":
+		return fmt.Sprintf("deploy the head of branch %s", s.Branch)
+	case s.RequestedVersion == "":
+		return "deploy the head of its branch"
+	case s.CurrentVersion == "":
+		return fmt.Sprintf("deploy %s", shortVersion(s.Type, s.RequestedVersion))
+	case s.CurrentVersion == s.RequestedVersion:
+		return fmt.Sprintf("redeploy %s (unchanged)", shortVersion(s.Type, s.RequestedVersion))
+	default:
+		return fmt.Sprintf("%s → %s", shortVersion(s.Type, s.CurrentVersion), shortVersion(s.Type, s.RequestedVersion))
+	}
+}
+
+// Lines renders the plan as text, one line per service
+func (p Plan) Lines() []string {
+	lines := []string{fmt.Sprintf("Environment %s state: %s", p.EnvironmentID, p.EnvironmentState)}
+	if !pkg.EnvStatus(p.EnvironmentState).AcceptsDeployment() {
+		lines = append(lines, "The environment doesn't accept a deployment yet, the deployment would wait for it to be ready")
+	}
+
+	for _, service := range p.Services {
+		lines = append(lines, fmt.Sprintf("%s %s (%s), state %s, currently %s: %s",
+			displayType(service.Type), orDash(service.Name), service.ID, service.State, orDash(shortVersion(service.Type, service.CurrentVersion)), service.Change))
+	}
+
+	return lines
+}
+
+// MarkdownSummary renders the plan as a Markdown table, suited for the GitHub job summary
+func (p Plan) MarkdownSummary() string {
+	var md strings.Builder
+
+	md.WriteString("### 📋 Qovery deployment plan (dry run)\n\n")
+	fmt.Fprintf(&md, "Environment `%s`: **%s**\n\n", p.EnvironmentID, p.EnvironmentState)
+
+	if len(p.Services) == 0 {
+		return md.String()
+	}
+
+	result := DeploymentResult{OrganizationID: p.OrganizationID, ProjectID: p.ProjectID, EnvironmentID: p.EnvironmentID}
+	md.WriteString("| Service | Type | ID | State | Current | Change | |\n")
+	md.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, service := range p.Services {
+		current := "-"
+		if service.CurrentVersion != "" {
+			current = "`" + shortVersion(service.Type, service.CurrentVersion) + "`"
+		}
+
+		fmt.Fprintf(&md, "| %s | %s | `%s` | %s | %s | %s | [Console](%s) |\n",
+			escapeMarkdownCell(orDash(service.Name)),
+			service.Type,
+			service.ID,
+			service.State,
+			current,
+			escapeMarkdownCell(service.Change),
+			result.ConsoleURL(service.ServiceResult),
+		)
+	}
+
+	return md.String()
+}
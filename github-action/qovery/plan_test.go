@@ -0,0 +1,56 @@
+package qovery
+
+import (
+	"context"
+	"testing"
+
+	"github-action/pkg"
+)
+
+func TestPlan(t *testing.T) {
+	// setup:
+	server := newTestServer()
+	defer server.Close()
+	server.AddApplication("env-id", "app2-id", "back")
+	server.AddDatabase("env-id", "db-id", "postgres")
+	server.SetStates("env-id", "DEPLOYED")
+	server.SetStates("app-id", "DEPLOYED")
+	server.SetStates("app2-id", "STOPPED")
+	server.SetStates("container-id", "DEPLOYED")
+	server.SetStates("db-id", "DEPLOYED")
+	server.SetDeployedVersion("app-id", "main", "0123456789abcdef0123456789abcdef01234567")
+	server.SetDeployedVersion("app2-id", "develop", "")
+	server.SetDeployedVersion("container-id", "", "v1")
+	services := pkg.ServicesDeployment{
+		Applications: []pkg.ApplicationDeployment{{ApplicationId: "app-id", GitCommitId: "fedcba9876543210fedcba9876543210fedcba98"}, {ApplicationId: "app2-id"}},
+		Containers:   []pkg.ContainerDeployment{{Id: "container-id", ImageTag: "v1"}},
+	}
+
+	// execute:
+	plan, err := PlanWithContext(context.Background(), server.Client(), "env-id", []string{"db-id"}, services)
+
+	// verify:
+	if err != nil {
+		t.Fatalf("expected no error but was %v", err)
+	}
+	if len(server.Deployments()) != 0 {
+		t.Fatalf("expected nothing to be deployed but was %+v", server.Deployments())
+	}
+	expected := map[string]string{
+		"db-id":        "redeploy",
+		"app-id":       "0123456 → fedcba9",
+		"app2-id":      "deploy the head of branch develop",
+		"container-id": "redeploy v1 (unchanged)",
+	}
+	if len(plan.Services) != len(expected) {
+		t.Fatalf("expected %d planned services but was %+v", len(expected), plan.Services)
+	}
+	for _, service := range plan.Services {
+		if service.Change != expected[service.ID] {
+			t.Fatalf("%s: expected change %q but was %q", service.ID, expected[service.ID], service.Change)
+		}
+	}
+	if back := plan.Services[2]; back.Name != "back" || back.State != "STOPPED" {
+		t.Fatalf("expected back to be resolved with its state but was %+v", back)
+	}
+}
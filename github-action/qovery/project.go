@@ -1,12 +1,17 @@
 package qovery
 
 import (
+	"context"
 	"fmt"
 	"github-action/pkg"
 )
 
 func GetProjectIdByName(qoveryAPIClient pkg.QoveryAPIClient, orgId string, name string) (string, error) {
-	projects, err := qoveryAPIClient.ListProjects(orgId)
+	return GetProjectIdByNameWithContext(context.Background(), qoveryAPIClient, orgId, name)
+}
+
+func GetProjectIdByNameWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, orgId string, name string) (string, error) {
+	projects, err := qoveryAPIClient.ListProjectsWithContext(ctx, orgId)
 	if err != nil {
 		return "", err
 	}
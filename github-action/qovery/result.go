@@ -0,0 +1,147 @@
+package qovery
+
+import (
+	"time"
+
+	"github-action/pkg"
+)
+
+const (
+	ServiceTypeApplication = "application"
+	ServiceTypeContainer   = "container"
+	ServiceTypeDatabase    = "database"
+)
+
+// displayType returns the service type as shown at the start of a log line, e.g. "Application"
+func displayType(serviceType string) string {
+	switch serviceType {
+	case ServiceTypeApplication:
+		return "Application"
+	case ServiceTypeContainer:
+		return "Container"
+	case ServiceTypeDatabase:
+		return "Database"
+	default:
+		return serviceType
+	}
+}
+
+// ServiceResult is the outcome of the deployment of a single service
+type ServiceResult struct {
+	Type                    string `json:"type"`
+	ID                      string `json:"id"`
+	Name                    string `json:"name,omitempty"`
+	RequestedVersion        string `json:"requested_version,omitempty"`
+	State                   string `json:"state"`
+	ServiceDeploymentStatus string `json:"service_deployment_status,omitempty"`
+	Deployed                bool   `json:"deployed"`
+	// Skipped is set when the service was not deployed, already running the requested commit or image tag
+	Skipped bool `json:"skipped,omitempty"`
+	// StartedAt is when the deployment of the service was launched, zero if it wasn't
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// DeploymentResult is the outcome of a deployment, partially filled when the deployment fails midway
+type DeploymentResult struct {
+	OrganizationID   string          `json:"organization_id,omitempty"`
+	ProjectID        string          `json:"project_id,omitempty"`
+	EnvironmentID    string          `json:"environment_id"`
+	EnvironmentState string          `json:"environment_state"`
+	Services         []ServiceResult `json:"services"`
+	StartedAt        time.Time       `json:"started_at"`
+	FinishedAt       time.Time       `json:"finished_at"`
+}
+
+// Service returns the result of the service with the given ID, nil if the service is not part of the deployment
+func (r *DeploymentResult) Service(id string) *ServiceResult {
+	for i := range r.Services {
+		if r.Services[i].ID == id {
+			return &r.Services[i]
+		}
+	}
+
+	return nil
+}
+
+// state returns the state of the service, typed after its kind
+func (s ServiceResult) state() pkg.State {
+	switch s.Type {
+	case ServiceTypeContainer:
+		return pkg.ContStatus(s.State)
+	case ServiceTypeDatabase:
+		return pkg.DbStatus(s.State)
+	default:
+		return pkg.AppStatus(s.State)
+	}
+}
+
+// Append merges the result of a following deployment step into this one, e.g. services deployed after a database.
+// The environment state is the one of the last step, the deployment spanning from the first start to the last finish.
+func (r *DeploymentResult) Append(other *DeploymentResult) {
+	if other == nil {
+		return
+	}
+
+	r.Services = append(r.Services, other.Services...)
+	if other.EnvironmentState != "" {
+		r.EnvironmentState = other.EnvironmentState
+	}
+	if r.StartedAt.IsZero() {
+		r.StartedAt = other.StartedAt
+	}
+	if !other.FinishedAt.IsZero() {
+		r.FinishedAt = other.FinishedAt
+	}
+}
+
+// SetNames fills the service names from a map of names by ID
+func (r *DeploymentResult) SetNames(names map[string]string) {
+	for i := range r.Services {
+		if name, ok := names[r.Services[i].ID]; ok {
+			r.Services[i].Name = name
+		}
+	}
+}
+
+// Duration returns how long the deployment of the service took from its launch, 0 if it didn't complete
+func (s ServiceResult) Duration() time.Duration {
+	if s.StartedAt.IsZero() || s.FinishedAt.IsZero() {
+		return 0
+	}
+
+	return s.FinishedAt.Sub(s.StartedAt)
+}
+
+// Launched tells whether Qovery accepted the deployment of at least one of the services
+func (r DeploymentResult) Launched() bool {
+	for _, service := range r.Services {
+		if !service.StartedAt.IsZero() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ServiceIDs returns the IDs of the services of the given type
+func (r DeploymentResult) ServiceIDs(serviceType string) []string {
+	var ids []string
+	for _, service := range r.Services {
+		if service.Type == serviceType {
+			ids = append(ids, service.ID)
+		}
+	}
+
+	return ids
+}
+
+// ServiceStates returns the final state of every service, by ID
+func (r DeploymentResult) ServiceStates() map[string]string {
+	states := map[string]string{}
+	for _, service := range r.Services {
+		states[service.ID] = service.State
+	}
+
+	return states
+}
@@ -0,0 +1,125 @@
+package qovery
+
+import (
+	"context"
+	"fmt"
+
+	"github-action/pkg"
+)
+
+// ListServicesWithContext returns the applications, containers and databases of an environment, state left empty
+func ListServicesWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string) ([]ServiceResult, error) {
+	var services []ServiceResult
+
+	applications, err := qoveryAPIClient.ListApplicationsWithContext(ctx, environmentId)
+	if err != nil {
+		return nil, err
+	}
+	for _, app := range applications {
+		services = append(services, ServiceResult{Type: ServiceTypeApplication, ID: app.ID, Name: app.Name})
+	}
+
+	containers, err := qoveryAPIClient.ListContainersWithContext(ctx, environmentId)
+	if err != nil {
+		return nil, err
+	}
+	for _, container := range containers {
+		services = append(services, ServiceResult{Type: ServiceTypeContainer, ID: container.ID, Name: container.Name})
+	}
+
+	databases, err := qoveryAPIClient.ListDatabasesWithContext(ctx, environmentId)
+	if err != nil {
+		return nil, err
+	}
+	for _, db := range databases {
+		services = append(services, ServiceResult{Type: ServiceTypeDatabase, ID: db.ID, Name: db.Name})
+	}
+
+	return services, nil
+}
+
+// ListServiceNamesWithContext returns the names of the applications, containers and databases of an environment, by ID
+func ListServiceNamesWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string) (map[string]string, error) {
+	services, err := ListServicesWithContext(ctx, qoveryAPIClient, environmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for _, service := range services {
+		names[service.ID] = service.Name
+	}
+
+	return names, nil
+}
+
+// GetServiceStateWithContext fills the state of a service from its status endpoint, returning it typed after the kind of service
+func GetServiceStateWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, service *ServiceResult) (pkg.State, error) {
+	var state pkg.State
+	switch service.Type {
+	case ServiceTypeApplication:
+		status, err := qoveryAPIClient.GetApplicationStatusWithContext(ctx, service.ID)
+		if err != nil {
+			return nil, err
+		}
+		state = status.State
+		service.State = string(status.State)
+		service.ServiceDeploymentStatus = status.ServiceDeploymentStatus
+	case ServiceTypeContainer:
+		status, err := qoveryAPIClient.GetContainerStatusWithContext(ctx, service.ID)
+		if err != nil {
+			return nil, err
+		}
+		state = status.State
+		service.State = string(status.State)
+		service.ServiceDeploymentStatus = status.ServiceDeploymentStatus
+	case ServiceTypeDatabase:
+		status, err := qoveryAPIClient.GetDatabaseStatusWithContext(ctx, service.ID)
+		if err != nil {
+			return nil, err
+		}
+		state = status.State
+		service.State = string(status.State)
+		service.ServiceDeploymentStatus = status.ServiceDeploymentStatus
+	default:
+		return nil, fmt.Errorf("unknown service type %s", service.Type)
+	}
+
+	service.Deployed = service.State == string(pkg.AppStatusDeployed)
+	return state, nil
+}
+
+// ResolveServiceKeysWithContext turns values given by service ID or name into values by service ID, for services of the given type
+func ResolveServiceKeysWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, serviceType string, values map[string]string) (map[string]string, error) {
+	services, err := ListServicesWithContext(ctx, qoveryAPIClient, environmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := map[string]string{}
+	for key, value := range values {
+		id := ""
+		for _, service := range services {
+			if service.Type != serviceType {
+				continue
+			}
+			if service.ID == key {
+				id = service.ID
+				break
+			}
+			if service.Name == key && id == "" {
+				id = service.ID
+			}
+		}
+
+		if id == "" {
+			return nil, fmt.Errorf("can't find %s with ID or name %v! (it's case sensitive)", serviceType, key)
+		}
+		if _, ok := resolved[id]; ok {
+			return nil, fmt.Errorf("%s %s is given more than once, by ID and by name", serviceType, id)
+		}
+		resolved[id] = value
+	}
+
+	return resolved, nil
+}
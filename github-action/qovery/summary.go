@@ -0,0 +1,118 @@
+package qovery
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github-action/pkg"
+)
+
+const consoleURL = "https://console.qovery.com"
+
+// ConsoleURL returns the link to the service page in the Qovery console
+func (r DeploymentResult) ConsoleURL(service ServiceResult) string {
+	// containers are displayed as applications in the console
+	serviceType := "application"
+	if service.Type == ServiceTypeDatabase {
+		serviceType = "database"
+	}
+
+	return fmt.Sprintf("%s/organization/%s/project/%s/environment/%s/%s/%s/general",
+		consoleURL, r.OrganizationID, r.ProjectID, r.EnvironmentID, serviceType, service.ID)
+}
+
+// MarkdownSummary renders the deployment result as a Markdown table, suited for the GitHub job summary
+func (r DeploymentResult) MarkdownSummary() string {
+	var md strings.Builder
+
+	fmt.Fprintf(&md, "### %s Qovery deployment\n\n", stateIcon(pkg.EnvStatus(r.EnvironmentState), r.succeeded()))
+	fmt.Fprintf(&md, "Environment `%s`: **%s**\n\n", r.EnvironmentID, r.EnvironmentState)
+
+	if len(r.Services) == 0 {
+		return md.String()
+	}
+
+	md.WriteString("| Service | Type | ID | Commit / Tag | State | Deployment status | Duration | |\n")
+	md.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, service := range r.Services {
+		name := service.Name
+		if name == "" {
+			name = "-"
+		}
+
+		icon := stateIcon(service.state(), service.Deployed)
+		duration := "-"
+		if d := service.Duration(); d > 0 {
+			duration = d.Round(time.Second).String()
+		}
+		if service.Skipped {
+			icon = "⏭️"
+			duration = "skipped, up to date"
+		}
+
+		fmt.Fprintf(&md, "| %s | %s | `%s` | %s | %s %s | %s | %s | [Console](%s) |\n",
+			escapeMarkdownCell(name),
+			service.Type,
+			service.ID,
+			formatVersion(service),
+			icon,
+			service.State,
+			orDash(service.ServiceDeploymentStatus),
+			duration,
+			r.ConsoleURL(service),
+		)
+	}
+
+	return md.String()
+}
+
+func (r DeploymentResult) succeeded() bool {
+	for _, service := range r.Services {
+		if !service.Deployed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func stateIcon(state pkg.State, deployed bool) string {
+	if deployed {
+		return "✅"
+	}
+	if state.IsError() {
+		return "❌"
+	}
+
+	return "❔"
+}
+
+func formatVersion(service ServiceResult) string {
+	if service.RequestedVersion == "" {
+		return "-"
+	}
+
+	return "`" + shortVersion(service.Type, service.RequestedVersion) + "`"
+}
+
+// shortVersion abbreviates full git SHAs, unreadable in a table
+func shortVersion(serviceType string, version string) string {
+	if serviceType == ServiceTypeApplication && len(version) == 40 {
+		return version[:7]
+	}
+
+	return version
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+func escapeMarkdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
@@ -0,0 +1,38 @@
+package qovery
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownSummary(t *testing.T) {
+	// setup:
+	start := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	result := DeploymentResult{
+		OrganizationID:   "org-id",
+		ProjectID:        "project-id",
+		EnvironmentID:    "env-id",
+		EnvironmentState: "DEPLOYMENT_ERROR",
+		StartedAt:        start,
+		Services: []ServiceResult{
+			{Type: ServiceTypeApplication, ID: "app-id", Name: "front", RequestedVersion: "0123456789abcdef0123456789abcdef01234567", State: "DEPLOYED", ServiceDeploymentStatus: "UP_TO_DATE", Deployed: true, StartedAt: start.Add(time.Minute), FinishedAt: start.Add(150 * time.Second)},
+			{Type: ServiceTypeDatabase, ID: "db-id", Name: "postgres", State: "DEPLOYMENT_ERROR"},
+		},
+	}
+
+	// execute:
+	summary := result.MarkdownSummary()
+
+	// verify:
+	expectedLines := []string{
+		"### ❌ Qovery deployment",
+		"| front | application | `app-id` | `0123456` | ✅ DEPLOYED | UP_TO_DATE | 1m30s | [Console](https://console.qovery.com/organization/org-id/project/project-id/environment/env-id/application/app-id/general) |",
+		"| postgres | database | `db-id` | - | ❌ DEPLOYMENT_ERROR | - | - | [Console](https://console.qovery.com/organization/org-id/project/project-id/environment/env-id/database/db-id/general) |",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(summary, line) {
+			t.Fatalf("expected summary to contain:\n%s\nbut was:\n%s", line, summary)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package qovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github-action/pkg"
+)
+
+// WaitForEnvironmentStateWithContext waits, within opts.DeploymentTimeout, for the environment to reach the target state.
+// It fails as soon as the environment reaches an error state other than the target one.
+func WaitForEnvironmentStateWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, target pkg.EnvStatus, opts DeployOptions) (pkg.EnvStatus, error) {
+	return waitForEnvironmentState(ctx, qoveryAPIClient, environmentId, target, false, opts)
+}
+
+// WaitForEnvironmentActionWithContext waits, within opts.DeploymentTimeout, for the environment to reach the target state of an action just requested.
+// It fails as soon as the environment reaches another final state, a final state read before the environment was seen
+// queued or in progress being taken for the one it had before the action, until opts.StaleStateDelay.
+func WaitForEnvironmentActionWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, target pkg.EnvStatus, opts DeployOptions) (pkg.EnvStatus, error) {
+	return waitForEnvironmentState(ctx, qoveryAPIClient, environmentId, target, true, opts)
+}
+
+func waitForEnvironmentState(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, target pkg.EnvStatus, afterAction bool, opts DeployOptions) (pkg.EnvStatus, error) {
+	logger := opts.logger()
+	startedAt := time.Now()
+	waitCtx, cancelWait := withOptionalTimeout(ctx, opts.DeploymentTimeout)
+	defer cancelWait()
+
+	logger.Group(fmt.Sprintf("Waiting for environment to be %s", target))
+	defer logger.EndGroup()
+
+	state := pkg.EnvStatusUnknown
+	progressed := false
+	for waitCtx.Err() == nil {
+		status, err := qoveryAPIClient.GetEnvironmentStatusWithContext(waitCtx, environmentId)
+		if err != nil {
+			return state, fmt.Errorf("error while trying to get environment status: %w", err)
+		}
+
+		state = status.State
+		logger.Printf("Environment state: %s", state)
+		warnUnknownState(logger, state)
+
+		if state == target {
+			return state, nil
+		}
+		progressed = progressed || state.IsInProgress()
+		if afterAction && isDeploymentOver(state, progressed, startedAt, opts) {
+			return state, fmt.Errorf("error: environment reached state %s while waiting for %s", state, target)
+		}
+		if !afterAction && state.IsError() {
+			return state, fmt.Errorf("error: environment reached state %s while waiting for %s", state, target)
+		}
+
+		if err := pkg.SleepWithContext(waitCtx, opts.PollInterval); err != nil {
+			break
+		}
+	}
+
+	return state, fmt.Errorf("error: stopped waiting for environment to be %s: %w", target, waitCtx.Err())
+}
+
+// CancelDeploymentWithContext cancels the deployment ongoing in the environment and waits for the environment to be CANCELED
+func CancelDeploymentWithContext(ctx context.Context, qoveryAPIClient pkg.QoveryAPIClient, environmentId string, opts DeployOptions) error {
+	if err := qoveryAPIClient.CancelEnvironmentDeploymentWithContext(ctx, environmentId); err != nil {
+		return fmt.Errorf("error while trying to cancel the deployment: %w", err)
+	}
+
+	_, err := WaitForEnvironmentActionWithContext(ctx, qoveryAPIClient, environmentId, pkg.EnvStatusCancelled, opts)
+	return err
+}
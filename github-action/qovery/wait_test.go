@@ -0,0 +1,118 @@
+package qovery
+
+import (
+	"context"
+	"testing"
+
+	"github-action/pkg"
+)
+
+func TestWaitForEnvironmentState(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		name          string
+		states        []string
+		target        pkg.EnvStatus
+		expectedState pkg.EnvStatus
+		expectedError bool
+	}{
+		{name: "reached", states: []string{"DEPLOYING", "DEPLOYED"}, target: pkg.EnvStatusDeployed, expectedState: pkg.EnvStatusDeployed},
+		{name: "error state", states: []string{"DEPLOYING", "DEPLOYMENT_ERROR"}, target: pkg.EnvStatusDeployed, expectedState: pkg.EnvStatusDeploymentError, expectedError: true},
+		{name: "error state as target", states: []string{"STOPPING", "STOP_ERROR"}, target: pkg.EnvStatusStopError, expectedState: pkg.EnvStatusStopError},
+	}
+
+	for _, tc := range testCases {
+		server := newTestServer()
+		server.SetStates("env-id", tc.states...)
+
+		// execute:
+		state, err := WaitForEnvironmentStateWithContext(context.Background(), server.Client(), "env-id", tc.target, testDeployOptions)
+		server.Close()
+
+		// verify:
+		if tc.expectedError != (err != nil) {
+			t.Fatalf("%s: expected error to be %v but was %v", tc.name, tc.expectedError, err)
+		}
+		if state != tc.expectedState {
+			t.Errorf("%s: expected state %s but was %s", tc.name, tc.expectedState, state)
+		}
+	}
+}
+
+func TestWaitForEnvironmentAction(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		name          string
+		states        []string
+		target        pkg.EnvStatus
+		expectedState pkg.EnvStatus
+		expectedError bool
+	}{
+		{name: "reached", states: []string{"STOPPING", "STOPPED"}, target: pkg.EnvStatusStopped, expectedState: pkg.EnvStatusStopped},
+		{name: "other final state", states: []string{"CANCELING", "DEPLOYED"}, target: pkg.EnvStatusCancelled, expectedState: pkg.EnvStatusDeployed, expectedError: true},
+		{name: "stale state first", states: []string{"DEPLOYED", "STOPPING", "STOPPED"}, target: pkg.EnvStatusStopped, expectedState: pkg.EnvStatusStopped},
+		{name: "stale error state first", states: []string{"DEPLOYMENT_ERROR", "STOP_QUEUED", "STOPPED"}, target: pkg.EnvStatusStopped, expectedState: pkg.EnvStatusStopped},
+		{name: "action without effect", states: []string{"DEPLOYED"}, target: pkg.EnvStatusStopped, expectedState: pkg.EnvStatusDeployed, expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		server := newTestServer()
+		server.SetStates("env-id", tc.states...)
+
+		// execute:
+		state, err := WaitForEnvironmentActionWithContext(context.Background(), server.Client(), "env-id", tc.target, testDeployOptions)
+		server.Close()
+
+		// verify:
+		if tc.expectedError != (err != nil) {
+			t.Fatalf("%s: expected error to be %v but was %v", tc.name, tc.expectedError, err)
+		}
+		if state != tc.expectedState {
+			t.Errorf("%s: expected state %s but was %s", tc.name, tc.expectedState, state)
+		}
+	}
+}
+
+func TestStopEnvironment(t *testing.T) {
+	// setup:
+	server := newTestServer()
+	defer server.Close()
+	client := server.Client()
+
+	// execute:
+	err := client.StopEnvironmentWithContext(context.Background(), "env-id")
+	if err != nil {
+		t.Fatalf("expected no error but was %s", err)
+	}
+	state, err := WaitForEnvironmentActionWithContext(context.Background(), client, "env-id", pkg.EnvStatusStopped, testDeployOptions)
+
+	// verify:
+	if err != nil {
+		t.Fatalf("expected no error but was %s", err)
+	}
+	if state != pkg.EnvStatusStopped {
+		t.Errorf("expected state %s but was %s", pkg.EnvStatusStopped, state)
+	}
+	if actions := server.Actions(); len(actions) != 1 || actions[0] != "stop env-id" {
+		t.Errorf("expected a single stop action but was %v", actions)
+	}
+}
+
+func TestCancelDeployment(t *testing.T) {
+	// setup:
+	server := newTestServer()
+	defer server.Close()
+	server.SetStates("env-id", "DEPLOYING")
+	server.OnAction("env-id", "cancelDeployment", "DEPLOYING", "CANCELING", "CANCELED")
+
+	// execute:
+	err := CancelDeploymentWithContext(context.Background(), server.Client(), "env-id", testDeployOptions)
+
+	// verify:
+	if err != nil {
+		t.Fatalf("expected no error but was %s", err)
+	}
+	if actions := server.Actions(); len(actions) != 1 || actions[0] != "cancelDeployment env-id" {
+		t.Errorf("expected a single cancel action but was %v", actions)
+	}
+}
@@ -0,0 +1,499 @@
+// Package qoverytest provides an in-process fake of the Qovery API endpoints used by pkg.QoveryAPIClient,
+// so code built on top of the client can be tested without reaching the real API.
+package qoverytest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github-action/pkg"
+)
+
+// Token is the only API token accepted by the fake server
+const Token = "qoverytest-token"
+
+// default states served for resources without any scripted state
+const (
+	defaultState           = "DEPLOYED"
+	defaultDeploymentState = "DEPLOYING"
+	// emptyScriptState is served by a state script without any state
+	emptyScriptState = "UNKNOWN"
+)
+
+// environment actions accepted by the fake server, with the states an environment goes through by default
+var defaultActionStates = map[string][]string{
+	"cancelDeployment": {"CANCELING", "CANCELED"},
+	"stop":             {"STOPPING", "STOPPED"},
+	"restart":          {"RESTARTING", "RESTARTED"},
+}
+
+// Deployment is a deployment request accepted by the fake server
+type Deployment struct {
+	EnvironmentID string
+	Services      pkg.ServicesDeployment
+	DatabaseID    string
+}
+
+// Server is a fake Qovery API serving a scriptable set of organizations, projects, environments and services.
+// Resource states are scripted as sequences: each status call serves the next state, the last one sticking forever.
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	organizations []pkg.Organization
+	projects      map[string][]pkg.Project
+	environments  map[string][]pkg.Environment
+	applications  map[string][]pkg.Application
+	containers    map[string][]pkg.Container
+	databases     map[string][]pkg.Database
+	states        map[string]*stateScript
+	deployStates  map[string][]string
+	actionStates  map[string][]string
+	actions       []string
+	failures      map[string][]int
+	alwaysFailing map[string]int
+	deployments   []Deployment
+}
+
+type stateScript struct {
+	states []string
+	polls  int
+}
+
+func (s *stateScript) next() string {
+	if len(s.states) == 0 {
+		return emptyScriptState
+	}
+
+	i := s.polls
+	if i >= len(s.states) {
+		i = len(s.states) - 1
+	}
+	s.polls++
+
+	return s.states[i]
+}
+
+// NewServer starts a fake Qovery API server, to be closed by the caller once done.
+func NewServer() *Server {
+	s := &Server{
+		projects:      map[string][]pkg.Project{},
+		environments:  map[string][]pkg.Environment{},
+		applications:  map[string][]pkg.Application{},
+		containers:    map[string][]pkg.Container{},
+		databases:     map[string][]pkg.Database{},
+		states:        map[string]*stateScript{},
+		deployStates:  map[string][]string{},
+		actionStates:  map[string][]string{},
+		failures:      map[string][]int{},
+		alwaysFailing: map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// Client returns a QoveryAPIClient talking to the fake server
+func (s *Server) Client() pkg.QoveryAPIClient {
+	return pkg.NewQoveryAPIClient(s.Server.Client(), s.URL, Token, 10*time.Second)
+}
+
+func (s *Server) AddOrganization(id string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.organizations = append(s.organizations, pkg.Organization{ID: id, Name: name})
+}
+
+func (s *Server) AddProject(organizationId string, id string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.projects[organizationId] = append(s.projects[organizationId], pkg.Project{ID: id, Name: name})
+}
+
+func (s *Server) AddEnvironment(projectId string, id string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.environments[projectId] = append(s.environments[projectId], pkg.Environment{ID: id, Name: name})
+}
+
+func (s *Server) AddApplication(environmentId string, id string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.applications[environmentId] = append(s.applications[environmentId], pkg.Application{ID: id, Name: name})
+}
+
+func (s *Server) AddContainer(environmentId string, id string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.containers[environmentId] = append(s.containers[environmentId], pkg.Container{ID: id, Name: name})
+}
+
+func (s *Server) AddDatabase(environmentId string, id string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.databases[environmentId] = append(s.databases[environmentId], pkg.Database{ID: id, Name: name})
+}
+
+// SetDeployedVersion sets the commit deployed for an application, or the image tag deployed for a container.
+// A deployment accepted by the fake server sets them to the requested commit or tag.
+func (s *Server) SetDeployedVersion(id string, branch string, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if app := s.application(id); app != nil {
+		app.GitRepository.Branch = branch
+		app.GitRepository.DeployedCommitId = version
+	}
+	if container := s.container(id); container != nil {
+		container.Tag = version
+	}
+}
+
+// SetStates scripts the states served by the status endpoint of an environment or a service, one per status call.
+// e.g. `SetStates(envId, "QUEUED", "DEPLOYING", "DEPLOYING", "DEPLOYED")`, UNKNOWN being served when no state is given
+func (s *Server) SetStates(id string, states ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[id] = &stateScript{states: states}
+}
+
+// OnDeploy scripts the states served by the status endpoint of an environment or a service once a deployment involving it is accepted.
+// Without it, a deployed resource goes DEPLOYING then DEPLOYED.
+func (s *Server) OnDeploy(id string, states ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deployStates[id] = states
+}
+
+// OnAction scripts the states served by the status endpoint of an environment once the given action
+// ("cancelDeployment", "stop" or "restart") is accepted on it.
+func (s *Server) OnAction(environmentId string, action string, states ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.actionStates[action+" "+environmentId] = states
+}
+
+// FailNext makes the next calls to the given method and path (e.g. "GET", "/environment/id/status") fail with the given status codes, in order.
+func (s *Server) FailNext(method string, path string, statusCodes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := method + " " + path
+	s.failures[key] = append(s.failures[key], statusCodes...)
+}
+
+// FailAlways makes every call to the given method and path fail with the given status code, after the ones scripted with FailNext.
+func (s *Server) FailAlways(method string, path string, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.alwaysFailing[method+" "+path] = statusCode
+}
+
+// Actions returns the environment actions accepted so far, formatted as "<action> <environment id>"
+func (s *Server) Actions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.actions...)
+}
+
+// Deployments returns the deployment requests accepted so far
+func (s *Server) Deployments() []Deployment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Deployment(nil), s.deployments...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Token "+Token {
+		writeError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	key := r.Method + " " + r.URL.Path
+	if codes := s.failures[key]; len(codes) > 0 {
+		s.failures[key] = codes[1:]
+		writeError(w, codes[0], "injected failure")
+		return
+	}
+	if code, ok := s.alwaysFailing[key]; ok {
+		writeError(w, code, "injected failure")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == "GET" && len(parts) == 1 && parts[0] == "organization":
+		writePage(w, r, s.organizations)
+	case r.Method == "GET" && len(parts) == 3 && parts[0] == "organization" && parts[2] == "project":
+		writePage(w, r, s.projects[parts[1]])
+	case r.Method == "GET" && len(parts) == 3 && parts[0] == "project" && parts[2] == "environment":
+		writePage(w, r, s.environments[parts[1]])
+	case r.Method == "GET" && len(parts) == 3 && parts[0] == "environment" && parts[2] == "application":
+		writePage(w, r, s.applications[parts[1]])
+	case r.Method == "GET" && len(parts) == 3 && parts[0] == "environment" && parts[2] == "container":
+		writePage(w, r, s.containers[parts[1]])
+	case r.Method == "GET" && len(parts) == 3 && parts[0] == "environment" && parts[2] == "database":
+		writePage(w, r, s.databases[parts[1]])
+	case r.Method == "GET" && len(parts) == 2 && parts[0] == "application":
+		s.handleGetApplication(w, parts[1])
+	case r.Method == "GET" && len(parts) == 2 && parts[0] == "container":
+		s.handleGetContainer(w, parts[1])
+	case r.Method == "GET" && len(parts) == 3 && parts[2] == "status":
+		s.handleStatus(w, parts[0], parts[1])
+	case r.Method == "POST" && len(parts) == 4 && parts[0] == "environment" && parts[2] == "service" && parts[3] == "deploy":
+		s.handleDeployServices(w, r, parts[1])
+	case r.Method == "POST" && len(parts) == 3 && parts[0] == "environment" && defaultActionStates[parts[2]] != nil:
+		s.handleEnvironmentAction(w, parts[1], parts[2])
+	case r.Method == "POST" && len(parts) == 3 && parts[0] == "database" && parts[2] == "deploy":
+		s.handleDeployDatabase(w, parts[1])
+	default:
+		writeError(w, http.StatusNotFound, "unknown route "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (s *Server) handleGetApplication(w http.ResponseWriter, applicationId string) {
+	if app := s.application(applicationId); app != nil {
+		writeJSON(w, http.StatusOK, app)
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "application "+applicationId+" not found")
+}
+
+func (s *Server) handleGetContainer(w http.ResponseWriter, containerId string) {
+	if container := s.container(containerId); container != nil {
+		writeJSON(w, http.StatusOK, container)
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "container "+containerId+" not found")
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, kind string, id string) {
+	if !s.exists(kind, id) {
+		writeError(w, http.StatusNotFound, kind+" "+id+" not found")
+		return
+	}
+
+	state := defaultState
+	if script, ok := s.states[id]; ok {
+		state = script.next()
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"id":                        id,
+		"state":                     state,
+		"service_deployment_status": "UP_TO_DATE",
+	})
+}
+
+func (s *Server) handleDeployServices(w http.ResponseWriter, r *http.Request, environmentId string) {
+	if !s.exists("environment", environmentId) {
+		writeError(w, http.StatusNotFound, "environment "+environmentId+" not found")
+		return
+	}
+
+	services := pkg.ServicesDeployment{}
+	if err := json.NewDecoder(r.Body).Decode(&services); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ids := []string{environmentId}
+	for _, app := range services.Applications {
+		if !s.exists("application", app.ApplicationId) {
+			writeError(w, http.StatusBadRequest, "application "+app.ApplicationId+" not found")
+			return
+		}
+		ids = append(ids, app.ApplicationId)
+	}
+	for _, cont := range services.Containers {
+		if !s.exists("container", cont.Id) {
+			writeError(w, http.StatusBadRequest, "container "+cont.Id+" not found")
+			return
+		}
+		ids = append(ids, cont.Id)
+	}
+
+	for _, app := range services.Applications {
+		if app.GitCommitId != "" {
+			s.application(app.ApplicationId).GitRepository.DeployedCommitId = app.GitCommitId
+		}
+	}
+	for _, cont := range services.Containers {
+		s.container(cont.Id).Tag = cont.ImageTag
+	}
+
+	s.startDeployment(ids...)
+	s.deployments = append(s.deployments, Deployment{EnvironmentID: environmentId, Services: services})
+	writeJSON(w, http.StatusOK, map[string]string{"id": environmentId})
+}
+
+func (s *Server) handleDeployDatabase(w http.ResponseWriter, databaseId string) {
+	environmentId := ""
+	for envId, databases := range s.databases {
+		for _, db := range databases {
+			if db.ID == databaseId {
+				environmentId = envId
+			}
+		}
+	}
+	if environmentId == "" {
+		writeError(w, http.StatusNotFound, "database "+databaseId+" not found")
+		return
+	}
+
+	s.startDeployment(environmentId, databaseId)
+	s.deployments = append(s.deployments, Deployment{EnvironmentID: environmentId, DatabaseID: databaseId})
+	writeJSON(w, http.StatusOK, map[string]string{"id": databaseId})
+}
+
+func (s *Server) handleEnvironmentAction(w http.ResponseWriter, environmentId string, action string) {
+	if !s.exists("environment", environmentId) {
+		writeError(w, http.StatusNotFound, "environment "+environmentId+" not found")
+		return
+	}
+
+	states, ok := s.actionStates[action+" "+environmentId]
+	if !ok {
+		states = defaultActionStates[action]
+	}
+	s.states[environmentId] = &stateScript{states: states}
+	s.actions = append(s.actions, action+" "+environmentId)
+	writeJSON(w, http.StatusAccepted, map[string]string{"id": environmentId})
+}
+
+func (s *Server) application(id string) *pkg.Application {
+	for _, apps := range s.applications {
+		for i := range apps {
+			if apps[i].ID == id {
+				return &apps[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) container(id string) *pkg.Container {
+	for _, containers := range s.containers {
+		for i := range containers {
+			if containers[i].ID == id {
+				return &containers[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) startDeployment(ids ...string) {
+	for _, id := range ids {
+		states, ok := s.deployStates[id]
+		if !ok {
+			states = []string{defaultDeploymentState, defaultState}
+		}
+		s.states[id] = &stateScript{states: states}
+	}
+}
+
+func (s *Server) exists(kind string, id string) bool {
+	switch kind {
+	case "environment":
+		for _, envs := range s.environments {
+			for _, env := range envs {
+				if env.ID == id {
+					return true
+				}
+			}
+		}
+	case "application":
+		for _, apps := range s.applications {
+			for _, app := range apps {
+				if app.ID == id {
+					return true
+				}
+			}
+		}
+	case "container":
+		for _, containers := range s.containers {
+			for _, container := range containers {
+				if container.ID == id {
+					return true
+				}
+			}
+		}
+	case "database":
+		for _, databases := range s.databases {
+			for _, db := range databases {
+				if db.ID == id {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// writePage serves results the way paginated Qovery endpoints do when `page_size` is asked for
+func writePage[T any](w http.ResponseWriter, r *http.Request, results []T) {
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if err != nil || pageSize <= 0 {
+		writeJSON(w, http.StatusOK, pkg.ResultPage[T]{Results: results})
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	start := (page - 1) * pageSize
+	if start > len(results) {
+		start = len(results)
+	}
+	end := start + pageSize
+	if end > len(results) {
+		end = len(results)
+	}
+
+	writeJSON(w, http.StatusOK, pkg.ResultPage[T]{
+		Page:     page,
+		PageSize: pageSize,
+		Results:  append([]T{}, results[start:end]...),
+	})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]string{
+		"error":   http.StatusText(statusCode),
+		"message": message,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
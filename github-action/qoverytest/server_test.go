@@ -0,0 +1,119 @@
+package qoverytest
+
+import (
+	"errors"
+	"testing"
+
+	"github-action/pkg"
+)
+
+func TestServerStates(t *testing.T) {
+	// setup:
+	testCases := []struct {
+		name     string
+		states   []string
+		expected []pkg.EnvStatus
+	}{
+		{name: "not scripted", expected: []pkg.EnvStatus{"DEPLOYED", "DEPLOYED"}},
+		{name: "progression", states: []string{"QUEUED", "DEPLOYING", "DEPLOYED"}, expected: []pkg.EnvStatus{"QUEUED", "DEPLOYING", "DEPLOYED", "DEPLOYED"}},
+		{name: "empty script", states: []string{}, expected: []pkg.EnvStatus{"UNKNOWN", "UNKNOWN"}},
+	}
+
+	for _, tc := range testCases {
+		server := NewServer()
+		server.AddEnvironment("project-id", "env-id", "production")
+		if tc.states != nil {
+			server.SetStates("env-id", tc.states...)
+		}
+
+		// execute:
+		var states []pkg.EnvStatus
+		for range tc.expected {
+			status, err := server.Client().GetEnvironmentStatus("env-id")
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %s", tc.name, err)
+			}
+			states = append(states, status.State)
+		}
+		server.Close()
+
+		// verify:
+		for i := range tc.expected {
+			if states[i] != tc.expected[i] {
+				t.Fatalf("%s: expected states %v but was %v", tc.name, tc.expected, states)
+			}
+		}
+	}
+}
+
+func TestServerOnDeploy(t *testing.T) {
+	// setup:
+	server := NewServer()
+	defer server.Close()
+	server.AddEnvironment("project-id", "env-id", "production")
+	server.AddApplication("env-id", "app-id", "front")
+	server.OnDeploy("app-id", "BUILDING", "BUILD_ERROR")
+	client := server.Client()
+
+	// execute:
+	err := client.DeployServices("env-id", pkg.ServicesDeployment{Applications: []pkg.ApplicationDeployment{{ApplicationId: "app-id", GitCommitId: "sha"}}})
+	building, _ := client.GetApplicationStatus("app-id")
+	failed, _ := client.GetApplicationStatus("app-id")
+
+	// verify:
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if building.State != "BUILDING" || failed.State != "BUILD_ERROR" {
+		t.Fatalf("expected the scripted deployment states but was %s then %s", building.State, failed.State)
+	}
+	if deployments := server.Deployments(); len(deployments) != 1 || deployments[0].EnvironmentID != "env-id" {
+		t.Fatalf("expected the deployment to be recorded but was %+v", deployments)
+	}
+}
+
+func TestServerFailNext(t *testing.T) {
+	// setup:
+	server := NewServer()
+	defer server.Close()
+	server.AddEnvironment("project-id", "env-id", "production")
+	server.FailNext("GET", "/environment/env-id/status", 409, 500)
+	client := server.Client()
+
+	// execute:
+	_, conflictErr := client.GetEnvironmentStatus("env-id")
+	_, serverErr := client.GetEnvironmentStatus("env-id")
+	status, err := client.GetEnvironmentStatus("env-id")
+
+	// verify:
+	var apiErr *pkg.APIError
+	if !errors.As(conflictErr, &apiErr) || apiErr.StatusCode != 409 {
+		t.Fatalf("expected a 409 API error but was %v", conflictErr)
+	}
+	if !errors.As(serverErr, &apiErr) || apiErr.StatusCode != 500 {
+		t.Fatalf("expected a 500 API error but was %v", serverErr)
+	}
+	if err != nil || status.State != "DEPLOYED" {
+		t.Fatalf("expected failures to be consumed but was %v, %v", status, err)
+	}
+}
+
+func TestServerFailAlways(t *testing.T) {
+	// setup:
+	server := NewServer()
+	defer server.Close()
+	server.AddEnvironment("project-id", "env-id", "production")
+	server.FailAlways("GET", "/environment/env-id/status", 409)
+	client := server.Client()
+
+	for i := 0; i < 3; i++ {
+		// execute:
+		_, err := client.GetEnvironmentStatus("env-id")
+
+		// verify:
+		var apiErr *pkg.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != 409 {
+			t.Fatalf("call %d: expected a 409 API error but was %v", i, err)
+		}
+	}
+}